@@ -37,6 +37,9 @@ def setup_api_server(cp: ConfigParser) -> None:
     reset_section('api_server', cp)
     cp['api_server']['port'] = ''
     cp['api_server']['metrics_url'] =  ''
+    cp['api_server']['compression_level'] = ''
+    cp['api_server']['cache_size'] = ''
+    cp['api_server']['cache_latest_ttl'] = ''
 
     if not already_set_up and \
             not yn_prompt('Do you wish to set up the API Server? (Y/n)\n'):
@@ -58,12 +61,111 @@ def setup_api_server(cp: ConfigParser) -> None:
     ' is needed which was exposed during the Node Exporter setup'
     ' (typically http://127.0.0.1:9100/metrics):\n')
 
+    print('--- Compression')
+    print('Responses are compressed for clients that accept it. The '
+          'compression level ranges from 1 (fastest) to 9 (smallest), '
+          'while 0 disables compression.')
+    compression_level = input('Please insert the compression level you '
+                              'would like the API Server to use: '
+                              '(default: 6)\n')
+    compression_level = '6' if compression_level == '' else compression_level
+
+    print('--- Cache')
+    print('Responses at an explicit height never change so they are cached '
+          'until the cache is full, while responses at the latest height are '
+          'cached for a few seconds.')
+    cache_size = input('Please insert the size of the cache in megabytes, '
+                       '0 disables caching: (default: 64)\n')
+    cache_size = '64' if cache_size == '' else cache_size
+    cache_latest_ttl = input('Please insert for how many seconds responses '
+                             'at the latest height are cached: '
+                             '(default: 2)\n')
+    cache_latest_ttl = '2' if cache_latest_ttl == '' else cache_latest_ttl
 
     cp['api_server']['port'] = port
     cp['api_server']['metrics_url'] = metrics_url
+    cp['api_server']['compression_level'] = compression_level
+    cp['api_server']['cache_size'] = cache_size
+    cp['api_server']['cache_latest_ttl'] = cache_latest_ttl
+
+
+def setup_api_tokens(cp: ConfigParser) -> None:
+    print('==== API Tokens')
+    print('Endpoints submitting transactions to nodes are only served to '
+          'requests carrying an API token granted the submit scope, in an '
+          '"Authorization: Bearer <token>" header.')
+
+    token_sections = [s for s in cp.sections() if s.startswith('token_')]
+    if len(token_sections) > 0 and \
+            not yn_prompt('API tokens are already set up. Do you wish to '
+                          'replace them with new ones? (Y/n)\n'):
+        return
+
+    if len(token_sections) == 0 and \
+            not yn_prompt('Do you wish to set up API tokens? (Y/n)\n'):
+        return
+
+    for section in token_sections:
+        cp.remove_section(section)
+
+    i = 0
+    while True:
+        token = input('Please insert the API token, a long random secret '
+                      'such as the output of `openssl rand -hex 32`:\n')
+        scopes = input('Please insert a comma separated list of scopes '
+                       'granted to the token: (default: submit)\n')
+        scopes = 'submit' if scopes == '' else scopes
+
+        section = 'token_' + str(i)
+        cp.add_section(section)
+        cp[section]['token'] = token
+        cp[section]['scopes'] = scopes
+        i += 1
+
+        if not yn_prompt('Do you want to add another API token? (Y/n)\n'):
+            break
+
+
+def setup_indexer(cp: ConfigParser) -> None:
+    print('==== Indexer')
+    print('The indexer follows a node and stores its blocks, transactions '
+          'and events locally, so that their history remains available once '
+          'the node prunes its state.')
+
+    already_set_up = is_already_set_up(cp, 'indexer')
+    if already_set_up and \
+            not yn_prompt('Indexer is already set up. Do you wish to clear '
+                          'the current config? (Y/n)\n'):
+        return
+
+    reset_section('indexer', cp)
+    cp['indexer']['node_name'] = ''
+    cp['indexer']['db_path'] = ''
+    cp['indexer']['start_height'] = ''
+
+    if not already_set_up and \
+            not yn_prompt('Do you wish to set up the indexer? (Y/n)\n'):
+        return
+
+    node_name = input('Please insert the name of the node to index, as set '
+                      'up in the list of nodes:\n')
+    db_path = input('Please insert the directory the index is stored in: '
+                    '(default: ../indexer_db)\n')
+    db_path = '../indexer_db' if db_path == '' else db_path
+    start_height = input('Please insert the height indexing starts from: '
+                         '(default: 1)\n')
+    start_height = '1' if start_height == '' else start_height
+
+    cp['indexer']['node_name'] = node_name
+    cp['indexer']['db_path'] = db_path
+    cp['indexer']['start_height'] = start_height
 
 
 def setup_all(cp: ConfigParser) -> None:
     setup_api_server(cp)
     print()
+    setup_indexer(cp)
+    print()
+    setup_api_tokens(cp)
+    print()
     print('Setup finished.')
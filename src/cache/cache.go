@@ -0,0 +1,121 @@
+// Package cache implements an in-memory LRU cache of responses bounded by
+// the total size of the cached data.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Approximate memory used by each entry apart from its key and value
+const entryOverhead = 64
+
+// Stats holds statistics of cache usage
+type Stats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Entries   int    `json:"entries"`
+	Bytes     int64  `json:"bytes"`
+	MaxBytes  int64  `json:"max_bytes"`
+}
+
+// entry is a cached value, expiring at given time unless it is zero
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// size returns approximate memory used by entry
+func (e *entry) size() int64 {
+	return int64(len(e.key) + len(e.value) + entryOverhead)
+}
+
+// Cache is an LRU cache safe for concurrent use
+type Cache struct {
+	mutex    sync.Mutex
+	maxBytes int64
+	bytes    int64
+	order    *list.List
+	entries  map[string]*list.Element
+	stats    Stats
+}
+
+// New returns cache holding at most maxBytes of data
+func New(maxBytes int64) *Cache {
+	return &Cache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns value cached under key if it exists and has not expired
+func (c *Cache) Get(key string) ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+
+	e := elem.Value.(*entry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		c.remove(elem)
+		c.stats.Misses++
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	c.stats.Hits++
+	return e.value, true
+}
+
+// Set caches value under key, evicting least recently used entries to
+// make room for it. A ttl of zero caches value until it is evicted.
+func (c *Cache) Set(key string, value []byte, ttl time.Duration) {
+	e := &entry{key: key, value: value}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+	if e.size() > c.maxBytes {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	for c.bytes+e.size() > c.maxBytes {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+
+	c.entries[key] = c.order.PushFront(e)
+	c.bytes += e.size()
+}
+
+// Stats returns statistics of cache usage
+func (c *Cache) Stats() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	stats.Bytes = c.bytes
+	stats.MaxBytes = c.maxBytes
+	return stats
+}
+
+// remove deletes element from cache
+func (c *Cache) remove(elem *list.Element) {
+	e := c.order.Remove(elem).(*entry)
+	delete(c.entries, e.key)
+	c.bytes -= e.size()
+}
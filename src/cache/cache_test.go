@@ -0,0 +1,97 @@
+package cache_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SimplyVC/oasis_api_server/src/cache"
+)
+
+func Test_Cache_Get(t *testing.T) {
+	c := cache.New(1024)
+	c.Set("block", []byte("Unicorn"), 0)
+
+	value, ok := c.Get("block")
+	if !ok || string(value) != "Unicorn" {
+		t.Errorf("cache returned unexpected value: got %v want %v",
+			string(value), "Unicorn")
+	}
+
+	if _, ok := c.Get("Unicorn"); ok {
+		t.Errorf("cache returned value for missing key")
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("cache returned unexpected stats: got %+v", stats)
+	}
+}
+
+func Test_Cache_Evict(t *testing.T) {
+	value := []byte(strings.Repeat("u", 100))
+	c := cache.New(500)
+	c.Set("a", value, 0)
+	c.Set("b", value, 0)
+	c.Set("c", value, 0)
+
+	// Using a makes b least recently used
+	c.Get("a")
+	c.Set("d", value, 0)
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("cache kept least recently used entry")
+	}
+	for _, key := range []string{"a", "c", "d"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("cache evicted entry %s", key)
+		}
+	}
+
+	stats := c.Stats()
+	if stats.Evictions != 1 || stats.Bytes > stats.MaxBytes {
+		t.Errorf("cache returned unexpected stats: got %+v", stats)
+	}
+}
+
+func Test_Cache_TooLarge(t *testing.T) {
+	c := cache.New(100)
+	c.Set("a", []byte(strings.Repeat("u", 100)), 0)
+
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("cache kept value larger than its size")
+	}
+}
+
+func Test_Cache_Replace(t *testing.T) {
+	c := cache.New(1024)
+	c.Set("a", []byte("Unicorn"), 0)
+	c.Set("a", []byte("Pegasus"), 0)
+
+	value, _ := c.Get("a")
+	if string(value) != "Pegasus" {
+		t.Errorf("cache returned unexpected value: got %v want %v",
+			string(value), "Pegasus")
+	}
+	if entries := c.Stats().Entries; entries != 1 {
+		t.Errorf("cache returned wrong number of entries: got %v want %v",
+			entries, 1)
+	}
+}
+
+func Test_Cache_TTL(t *testing.T) {
+	c := cache.New(1024)
+	c.Set("latest", []byte("Unicorn"), 10*time.Millisecond)
+
+	if _, ok := c.Get("latest"); !ok {
+		t.Errorf("cache expired entry too early")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := c.Get("latest"); ok {
+		t.Errorf("cache returned expired entry")
+	}
+	if entries := c.Stats().Entries; entries != 0 {
+		t.Errorf("cache kept expired entry: got %v entries", entries)
+	}
+}
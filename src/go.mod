@@ -10,6 +10,7 @@ replace (
 
 require (
 	github.com/Kubuxu/go-os-helper v0.0.1 // indirect
+	github.com/andybalholm/brotli v1.0.4
 	github.com/blevesearch/bleve v1.0.14
 	github.com/cenkalti/backoff/v4 v4.1.0
 	github.com/claudetech/ini v0.0.0-20140910072410-73e6100d9d51
@@ -21,6 +22,7 @@ require (
 	github.com/go-kit/kit v0.10.0
 	github.com/golang/snappy v0.0.3
 	github.com/gorilla/mux v1.7.4
+	github.com/gorilla/websocket v1.4.2
 	github.com/grpc-ecosystem/go-grpc-middleware v1.2.2
 	github.com/hpcloud/tail v1.0.0
 	github.com/jackpal/gateway v1.0.5 // indirect
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/SimplyVC/oasis_api_server/src/config"
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+)
+
+const (
+	// Prefix of sections of main configuration holding API tokens
+	tokenSectionPrefix = "token_"
+
+	// ScopeSubmit allows submitting transactions to nodes
+	ScopeSubmit = "submit"
+)
+
+// tokenScopes returns scopes granted to token by main configuration, or nil
+// if token isn't configured. Tokens are compared in constant time.
+func tokenScopes(token string) map[string]bool {
+	if len(token) == 0 {
+		return nil
+	}
+
+	for section, values := range config.GetMain() {
+		if !strings.HasPrefix(section, tokenSectionPrefix) ||
+			len(values["token"]) == 0 {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(values["token"]),
+			[]byte(token)) != 1 {
+			continue
+		}
+
+		scopes := make(map[string]bool)
+		for _, scope := range strings.Split(values["scopes"], ",") {
+			if scope = strings.TrimSpace(scope); len(scope) > 0 {
+				scopes[scope] = true
+			}
+		}
+		return scopes
+	}
+	return nil
+}
+
+// Authorized wraps handler so that it is only served to requests carrying
+// an API token granted given scope in an "Authorization: Bearer" header.
+func Authorized(scope string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := ""
+		header := r.Header.Get("Authorization")
+		if strings.HasPrefix(header, "Bearer ") {
+			token = strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+		}
+
+		scopes := tokenScopes(token)
+		if scopes == nil {
+			lgr.Error.Println("Request at " + r.URL.Path + " rejected, " +
+				"missing or unknown API token!")
+			w.Header().Add("Content-Type", "application/json")
+			w.Header().Set("WWW-Authenticate", `Bearer realm="oasis_api_server"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(responses.ErrorResponse{
+				Error: "Missing or unknown API token!"})
+			return
+		}
+		if !scopes[scope] {
+			lgr.Error.Println("Request at " + r.URL.Path + " rejected, " +
+				"API token isn't granted scope " + scope + "!")
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(responses.ErrorResponse{
+				Error: "API token isn't granted scope " + scope + "!"})
+			return
+		}
+		h(w, r)
+	}
+}
@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	conf "github.com/SimplyVC/oasis_api_server/src/config"
+	hdl "github.com/SimplyVC/oasis_api_server/src/handlers"
+)
+
+// Main configuration granting tokens their scopes
+const tokensConfig = `[api_server]
+port = 3000
+
+[token_0]
+token = Unicorn
+scopes = submit
+
+[token_1]
+token = Pegasus
+scopes =
+`
+
+func serveAuthorized(t *testing.T, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/api/consensus/submittx", nil)
+	if len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	calls := 0
+	rr := httptest.NewRecorder()
+	hdl.Authorized(hdl.ScopeSubmit,
+		countingHandler(&calls, `{"result":"Unicorn"}`)).ServeHTTP(rr, req)
+	if rr.Code == http.StatusOK && calls != 1 {
+		t.Errorf("handler was called %v times", calls)
+	}
+	return rr
+}
+
+func Test_Authorized(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokens")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "user_config_main.ini")
+	if err := ioutil.WriteFile(file, []byte(tokensConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	conf.SetMainFile(file)
+	conf.LoadMainConfiguration()
+	defer func() {
+		conf.SetMainFile("../config/user_config_main.ini")
+		conf.LoadMainConfiguration()
+	}()
+
+	tests := []struct {
+		token    string
+		status   int
+		expected string
+	}{
+		{"", http.StatusUnauthorized,
+			`{"error":"Missing or unknown API token!"}`},
+		{"Griffin", http.StatusUnauthorized,
+			`{"error":"Missing or unknown API token!"}`},
+		{"Pegasus", http.StatusForbidden,
+			`{"error":"API token isn't granted scope submit!"}`},
+		{"Unicorn", http.StatusOK, `{"result":"Unicorn"}`},
+	}
+	for _, test := range tests {
+		rr := serveAuthorized(t, test.token)
+		if status := rr.Code; status != test.status {
+			t.Errorf("handler returned wrong status code: got %v want %v",
+				status, test.status)
+		}
+		if strings.TrimSpace(rr.Body.String()) != test.expected {
+			t.Errorf("handler returned unexpected body: got %v want %v",
+				rr.Body.String(), test.expected)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+// buildParams holds inputs common to endpoints building transactions
+type buildParams struct {
+	socket   string
+	signer   signature.PublicKey
+	gasPrice quantity.Quantity
+	nonce    *uint64
+}
+
+// checkQuantity parses quantity given as a string representing an int
+func checkQuantity(recvQuantity string) (quantity.Quantity, bool) {
+	var q quantity.Quantity
+	if err := q.UnmarshalText([]byte(recvQuantity)); err != nil {
+		return q, false
+	}
+	return q, true
+}
+
+// checkBuildParams parses inputs common to endpoints building transactions,
+// returning an error message if any of them is invalid.
+func checkBuildParams(r *http.Request) (*buildParams, string) {
+	q := r.URL.Query()
+
+	confirmation, socket := checkNodeName(q.Get("name"))
+	if !confirmation {
+		return nil, "Node name requested doesn't exist"
+	}
+	params := &buildParams{socket: socket}
+
+	publicKey := q.Get("pubKey")
+	if len(publicKey) == 0 {
+		return nil, "pubKey can't be empty!"
+	}
+	if err := params.signer.UnmarshalText([]byte(publicKey)); err != nil {
+		lgr.Error.Println("Failed to UnmarshalText into PublicKey", err)
+		return nil, "Failed to UnmarshalText into PublicKey."
+	}
+
+	// Fee is only as high as gas price given, so there is no default
+	// which nodes with a minimum gas price would accept
+	recvGasPrice := q.Get("gas_price")
+	if len(recvGasPrice) == 0 {
+		return nil, "gas_price can't be empty!"
+	}
+	gasPrice, ok := checkQuantity(recvGasPrice)
+	if !ok {
+		return nil, "Unexpected value found, gas_price needs to be " +
+			"a string representing an int!"
+	}
+	params.gasPrice = gasPrice
+
+	if recvNonce := q.Get("nonce"); len(recvNonce) > 0 {
+		nonce, err := strconv.ParseUint(recvNonce, 10, 64)
+		if err != nil {
+			return nil, "Unexpected value found, nonce needs to be " +
+				"a string representing an int!"
+		}
+		params.nonce = &nonce
+	}
+	return params, ""
+}
+
+// checkAddressQuantity parses address and quantity of a transaction moving
+// tokens, returning an error message if either of them is invalid.
+func checkAddressQuantity(r *http.Request, addressParam string,
+	quantityParam string) (staking.Address, quantity.Quantity, string) {
+
+	var address staking.Address
+	var q quantity.Quantity
+	err := address.UnmarshalText([]byte(r.URL.Query().Get(addressParam)))
+	if err != nil {
+		lgr.Error.Println("Failed to UnmarshalText into Address", err)
+		return address, q, "Failed to UnmarshalText into Address."
+	}
+
+	q, ok := checkQuantity(r.URL.Query().Get(quantityParam))
+	if !ok {
+		return address, q, "Unexpected value found, " + quantityParam +
+			" needs to be a string representing an int!"
+	}
+	return address, q, ""
+}
+
+// checkCommissionSchedule parses commission schedule amendment given as a
+// comma separated list of start:rate steps and a comma separated list of
+// start:rate_min:rate_max bound steps.
+func checkCommissionSchedule(recvRates string, recvBounds string) (
+	*staking.CommissionSchedule, string) {
+
+	schedule := &staking.CommissionSchedule{}
+	if len(recvRates) > 0 {
+		for _, step := range strings.Split(recvRates, ",") {
+			fields := strings.Split(strings.TrimSpace(step), ":")
+			if len(fields) != 2 {
+				return nil, "Unexpected value found, rates needs to be a " +
+					"comma separated list of start:rate steps!"
+			}
+			start, err := strconv.ParseUint(fields[0], 10, 64)
+			rate, ok := checkQuantity(fields[1])
+			if err != nil || !ok {
+				return nil, "Unexpected value found, rates needs to be a " +
+					"comma separated list of start:rate steps!"
+			}
+			schedule.Rates = append(schedule.Rates, staking.CommissionRateStep{
+				Start: beacon.EpochTime(start), Rate: rate})
+		}
+	}
+
+	if len(recvBounds) > 0 {
+		for _, step := range strings.Split(recvBounds, ",") {
+			fields := strings.Split(strings.TrimSpace(step), ":")
+			if len(fields) != 3 {
+				return nil, "Unexpected value found, bounds needs to be a " +
+					"comma separated list of start:rate_min:rate_max steps!"
+			}
+			start, err := strconv.ParseUint(fields[0], 10, 64)
+			rateMin, okMin := checkQuantity(fields[1])
+			rateMax, okMax := checkQuantity(fields[2])
+			if err != nil || !okMin || !okMax {
+				return nil, "Unexpected value found, bounds needs to be a " +
+					"comma separated list of start:rate_min:rate_max steps!"
+			}
+			schedule.Bounds = append(schedule.Bounds,
+				staking.CommissionRateBoundStep{Start: beacon.EpochTime(start),
+					RateMin: rateMin, RateMax: rateMax})
+		}
+	}
+
+	if len(schedule.Rates) == 0 && len(schedule.Bounds) == 0 {
+		return nil, "rates and bounds can't both be empty!"
+	}
+	return schedule, ""
+}
+
+// buildTransaction assembles transaction of signer with given method and
+// body, filling in nonce of signer and fee of transaction, and responds
+// with it ready to be signed.
+func buildTransaction(w http.ResponseWriter, endpoint string,
+	params *buildParams, method transaction.MethodName, op transaction.Op,
+	body interface{}) {
+
+	// Attempt to load connection with consensus client
+	connection, co := loadConsensusClient(params.socket)
+
+	// Close connection once code underneath executes
+	defer connection.Close()
+
+	// If null object was retrieved send response
+	if co == nil {
+
+		// Stop code here faild to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to establish connection using socket: " +
+				params.socket})
+		return
+	}
+
+	// Retrieve nonce of signer unless it was given
+	signerAddress := staking.NewAddress(params.signer)
+	var nonce uint64
+	if params.nonce != nil {
+		nonce = *params.nonce
+	} else {
+		var err error
+		nonce, err = co.GetSignerNonce(context.Background(),
+			&consensus.GetSignerNonceRequest{
+				AccountAddress: signerAddress,
+				Height:         consensus.HeightLatest,
+			})
+		if err != nil {
+			json.NewEncoder(w).Encode(responses.ErrorResponse{
+				Error: "Failed to retrieve Signer Nonce!"})
+
+			lgr.Error.Println("Request at "+endpoint+" failed to retrieve "+
+				"Signer Nonce : ", err)
+			return
+		}
+	}
+
+	// Estimate gas needed by transaction
+	tx := transaction.NewTransaction(nonce, &transaction.Fee{}, method, body)
+	gas, err := co.EstimateGas(context.Background(),
+		&consensus.EstimateGasRequest{Signer: params.signer, Transaction: tx})
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to estimate Gas!"})
+
+		lgr.Error.Println("Request at "+endpoint+" failed to estimate "+
+			"Gas : ", err)
+		return
+	}
+
+	// Gas is at least the cost of operation set by staking parameters
+	consensusParameters, err := co.Staking().ConsensusParameters(
+		context.Background(), consensus.HeightLatest)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Consensus Parameters!"})
+
+		lgr.Error.Println("Request at "+endpoint+" failed to retrieve "+
+			"Consensus Parameters : ", err)
+		return
+	}
+	if opGas := consensusParameters.GasCosts[op]; opGas > gas {
+		gas = opGas
+	}
+
+	// Fee paid is gas multiplied by gas price
+	amount := quantity.NewFromUint64(uint64(gas))
+	if err := amount.Mul(&params.gasPrice); err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to compute Fee!"})
+
+		lgr.Error.Println("Request at "+endpoint+" failed to compute "+
+			"Fee : ", err)
+		return
+	}
+	tx.Fee = &transaction.Fee{Amount: *amount, Gas: gas}
+
+	// Chain context is part of context transaction is signed in
+	chainContext, err := getChainContext(params.socket)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Chain Context!"})
+
+		lgr.Error.Println("Request at "+endpoint+" failed to retrieve "+
+			"Chain Context : ", err)
+		return
+	}
+
+	raw := cbor.Marshal(tx)
+
+	// Responds with transaction ready to be signed
+	lgr.Info.Println("Request at " + endpoint + " responding with " +
+		"Unsigned Transaction!")
+	json.NewEncoder(w).Encode(responses.UnsignedTransactionResponse{
+		UnsignedTransaction: &responses.UnsignedTransaction{
+			Signer:           params.signer,
+			SignerAddress:    signerAddress,
+			Transaction:      tx,
+			GasPrice:         params.gasPrice,
+			Body:             body,
+			RawTransaction:   raw,
+			SignatureContext: signingContext(chainContext),
+			SigningHash:      signingHash(chainContext, raw),
+		}})
+}
+
+// BuildTransfer returns unsigned transaction transferring amount of tokens
+// from signer to an address
+func BuildTransfer(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving signer, gas price and nonce from query request
+	params, msg := checkBuildParams(r)
+	if len(msg) > 0 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
+	// Retrieving receiver and amount from query request
+	to, amount, msg := checkAddressQuantity(r, "to", "amount")
+	if len(msg) > 0 {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
+	buildTransaction(w, "/api/staking/buildtransfer", params,
+		staking.MethodTransfer, staking.GasOpTransfer,
+		&staking.Transfer{To: to, Amount: amount})
+}
+
+// BuildAddEscrow returns unsigned transaction escrowing amount of tokens of
+// signer to an account
+func BuildAddEscrow(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving signer, gas price and nonce from query request
+	params, msg := checkBuildParams(r)
+	if len(msg) > 0 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
+	// Retrieving escrow account and amount from query request
+	account, amount, msg := checkAddressQuantity(r, "account", "amount")
+	if len(msg) > 0 {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
+	buildTransaction(w, "/api/staking/buildaddescrow", params,
+		staking.MethodAddEscrow, staking.GasOpAddEscrow,
+		&staking.Escrow{Account: account, Amount: amount})
+}
+
+// BuildReclaimEscrow returns unsigned transaction reclaiming shares of
+// signer from an escrow account
+func BuildReclaimEscrow(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving signer, gas price and nonce from query request
+	params, msg := checkBuildParams(r)
+	if len(msg) > 0 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
+	// Retrieving escrow account and shares from query request
+	account, shares, msg := checkAddressQuantity(r, "account", "shares")
+	if len(msg) > 0 {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
+	buildTransaction(w, "/api/staking/buildreclaimescrow", params,
+		staking.MethodReclaimEscrow, staking.GasOpReclaimEscrow,
+		&staking.ReclaimEscrow{Account: account, Shares: shares})
+}
+
+// BuildAmendCommissionSchedule returns unsigned transaction amending
+// commission schedule of signer
+func BuildAmendCommissionSchedule(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving signer, gas price and nonce from query request
+	params, msg := checkBuildParams(r)
+	if len(msg) > 0 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
+	// Retrieving amended rates and bounds from query request
+	schedule, msg := checkCommissionSchedule(r.URL.Query().Get("rates"),
+		r.URL.Query().Get("bounds"))
+	if len(msg) > 0 {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
+	buildTransaction(w, "/api/staking/buildamendcommissionschedule", params,
+		staking.MethodAmendCommissionSchedule,
+		staking.GasOpAmendCommissionSchedule,
+		&staking.AmendCommissionSchedule{Amendment: *schedule})
+}
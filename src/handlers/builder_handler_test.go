@@ -0,0 +1,138 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	hdl "github.com/SimplyVC/oasis_api_server/src/handlers"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+)
+
+const (
+	// Public key and address used to build transactions
+	builderPublicKey = "AzJTHgUZKYGYVPoN5F8WLtMyEPh7OKpM1uJGQVRiZek="
+	builderAddress   = "oasis1qqqf342r78nz05dq2pa3wzh0w54k3ea49u6rqdhv"
+)
+
+func Test_BuildTransfer_BadNode(t *testing.T) {
+	rr := serveRequest(hdl.BuildTransfer, "/api/staking/buildtransfer",
+		map[string]string{"name": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Node name requested doesn't exist"}`)
+}
+
+func Test_BuildTransfer_EmptyPubKey(t *testing.T) {
+	rr := serveRequest(hdl.BuildTransfer, "/api/staking/buildtransfer",
+		map[string]string{"name": "Oasis_Local"})
+	checkErrorResponse(t, rr, `{"error":"pubKey can't be empty!"}`)
+}
+
+func Test_BuildTransfer_InvalidPubKey(t *testing.T) {
+	rr := serveRequest(hdl.BuildTransfer, "/api/staking/buildtransfer",
+		map[string]string{"name": "Oasis_Local", "pubKey": "Unicorn"})
+	checkErrorResponse(t, rr,
+		`{"error":"Failed to UnmarshalText into PublicKey."}`)
+}
+
+func Test_BuildTransfer_EmptyGasPrice(t *testing.T) {
+	rr := serveRequest(hdl.BuildTransfer, "/api/staking/buildtransfer",
+		map[string]string{"name": "Oasis_Local", "pubKey": builderPublicKey,
+			"to": builderAddress, "amount": "1000"})
+	checkErrorResponse(t, rr, `{"error":"gas_price can't be empty!"}`)
+}
+
+func Test_BuildTransfer_InvalidGasPrice(t *testing.T) {
+	rr := serveRequest(hdl.BuildTransfer, "/api/staking/buildtransfer",
+		map[string]string{"name": "Oasis_Local", "pubKey": builderPublicKey,
+			"gas_price": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, gas_price `+
+		`needs to be a string representing an int!"}`)
+}
+
+func Test_BuildTransfer_InvalidAddress(t *testing.T) {
+	rr := serveRequest(hdl.BuildTransfer, "/api/staking/buildtransfer",
+		map[string]string{"name": "Oasis_Local", "pubKey": builderPublicKey,
+			"gas_price": "1", "to": "Unicorn", "amount": "1000"})
+	checkErrorResponse(t, rr,
+		`{"error":"Failed to UnmarshalText into Address."}`)
+}
+
+func Test_BuildTransfer_InvalidAmount(t *testing.T) {
+	rr := serveRequest(hdl.BuildTransfer, "/api/staking/buildtransfer",
+		map[string]string{"name": "Oasis_Local", "pubKey": builderPublicKey,
+			"gas_price": "1", "to": builderAddress, "amount": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, amount `+
+		`needs to be a string representing an int!"}`)
+}
+
+func Test_BuildReclaimEscrow_InvalidShares(t *testing.T) {
+	rr := serveRequest(hdl.BuildReclaimEscrow,
+		"/api/staking/buildreclaimescrow",
+		map[string]string{"name": "Oasis_Local", "pubKey": builderPublicKey,
+			"gas_price": "1", "account": builderAddress, "shares": "-5"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, shares `+
+		`needs to be a string representing an int!"}`)
+}
+
+func Test_BuildAmendCommissionSchedule_Empty(t *testing.T) {
+	rr := serveRequest(hdl.BuildAmendCommissionSchedule,
+		"/api/staking/buildamendcommissionschedule",
+		map[string]string{"name": "Oasis_Local", "pubKey": builderPublicKey,
+			"gas_price": "1"})
+	checkErrorResponse(t, rr,
+		`{"error":"rates and bounds can't both be empty!"}`)
+}
+
+func Test_BuildAmendCommissionSchedule_InvalidRates(t *testing.T) {
+	rr := serveRequest(hdl.BuildAmendCommissionSchedule,
+		"/api/staking/buildamendcommissionschedule",
+		map[string]string{"name": "Oasis_Local", "pubKey": builderPublicKey,
+			"gas_price": "1", "rates": "100:5000,Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, rates needs `+
+		`to be a comma separated list of start:rate steps!"}`)
+}
+
+func Test_BuildAmendCommissionSchedule_InvalidBounds(t *testing.T) {
+	rr := serveRequest(hdl.BuildAmendCommissionSchedule,
+		"/api/staking/buildamendcommissionschedule",
+		map[string]string{"name": "Oasis_Local", "pubKey": builderPublicKey,
+			"gas_price": "1", "bounds": "100:5000"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, bounds needs `+
+		`to be a comma separated list of start:rate_min:rate_max steps!"}`)
+}
+
+func Test_BuildAddEscrow(t *testing.T) {
+	rr := serveRequest(hdl.BuildAddEscrow, "/api/staking/buildaddescrow",
+		map[string]string{"name": "Oasis_Local", "pubKey": builderPublicKey,
+			"gas_price": "2", "account": builderAddress,
+			"amount": "100000000000"})
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	unsigned := &responses.UnsignedTransactionResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), unsigned)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	result := unsigned.UnsignedTransaction
+	if result == nil || result.Transaction == nil ||
+		result.Transaction.Fee == nil || len(result.SigningHash) != 32 {
+		t.Fatalf("handler returned unexpected body: got %v",
+			rr.Body.String())
+	}
+
+	// Fee is gas multiplied by gas price given
+	fee := quantity.NewFromUint64(uint64(result.Transaction.Fee.Gas) * 2)
+	if result.Transaction.Fee.Amount.Cmp(fee) != 0 {
+		t.Errorf("handler returned wrong fee: got %v want %v",
+			result.Transaction.Fee.Amount, fee)
+	}
+	if result.GasPrice.Cmp(quantity.NewFromUint64(2)) != 0 {
+		t.Errorf("handler returned wrong gas price: got %v want %v",
+			result.GasPrice, 2)
+	}
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/textproto"
+	"time"
+
+	"github.com/SimplyVC/oasis_api_server/src/cache"
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+)
+
+// Header telling whether response was served from cache
+const cacheHeader = "X-Cache"
+
+var (
+	// Cache of responses, nil if caching is disabled
+	responseCache *cache.Cache
+
+	// How long responses at latest height are cached for
+	latestCacheTTL time.Duration
+)
+
+// InitCache enables caching of responses using cache of given size.
+// Responses at an explicit height never change so they are kept until
+// evicted, while responses at latest height, or at a height pinned relative
+// to it, are kept for latestTTL.
+func InitCache(maxBytes int64, latestTTL time.Duration) {
+	responseCache = cache.New(maxBytes)
+	latestCacheTTL = latestTTL
+}
+
+// cacheRecorder captures response written by handler. Headers are
+// captured apart from those of wrapped writer if header is set.
+type cacheRecorder struct {
+	http.ResponseWriter
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// Header returns headers of response
+func (cr *cacheRecorder) Header() http.Header {
+	if cr.header != nil {
+		return cr.header
+	}
+	return cr.ResponseWriter.Header()
+}
+
+// WriteHeader records status code of response
+func (cr *cacheRecorder) WriteHeader(status int) {
+	if cr.status == 0 {
+		cr.status = status
+	}
+}
+
+// Write records body of response
+func (cr *cacheRecorder) Write(p []byte) (int, error) {
+	if cr.status == 0 {
+		cr.status = http.StatusOK
+	}
+	return cr.body.Write(p)
+}
+
+// encodeCachedResponse encodes headers and body of response in the wire
+// format of HTTP, headers first followed by an empty line
+func encodeCachedResponse(header http.Header, body []byte) []byte {
+	var buf bytes.Buffer
+	header.Write(&buf)
+	buf.WriteString("\r\n")
+	buf.Write(body)
+	return buf.Bytes()
+}
+
+// decodeCachedResponse decodes headers and body of response encoded by
+// encodeCachedResponse
+func decodeCachedResponse(value []byte) (http.Header, []byte, error) {
+	reader := bufio.NewReader(bytes.NewReader(value))
+	header, err := textproto.NewReader(reader).ReadMIMEHeader()
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return http.Header(header), body, nil
+}
+
+// Cached wraps handler so that its responses are served from cache. Keys
+// are made of requested path and query parameters, which include name of
+// node, and error responses are never cached. Headers set by handler are
+// cached with body and replayed on a hit.
+func Cached(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if responseCache == nil {
+			h(w, r)
+			return
+		}
+
+		// Only responses at an explicit height are kept until evicted, as
+		// those pinned from latest height are soon superseded
+		ttl := time.Duration(0)
+		height := checkHeight(r.URL.Query().Get("height"))
+		if height == -1 {
+			h(w, r)
+			return
+		}
+		if height == consensus.HeightLatest || pinnedFromLatest(r) {
+			if latestCacheTTL <= 0 {
+				h(w, r)
+				return
+			}
+			ttl = latestCacheTTL
+		}
+
+		// Query is encoded with sorted keys so equal requests share a key
+		key := r.URL.Path + "?" + r.URL.Query().Encode()
+		if value, ok := responseCache.Get(key); ok {
+			header, body, err := decodeCachedResponse(value)
+			if err == nil {
+				for name, values := range header {
+					w.Header()[name] = values
+				}
+				w.Header().Set(cacheHeader, "HIT")
+				w.Write(body)
+				return
+			}
+			lgr.Error.Println("Failed to decode cached response : ", err)
+		}
+
+		rec := &cacheRecorder{ResponseWriter: w, header: http.Header{}}
+		h(rec, r)
+
+		body := rec.body.Bytes()
+		if rec.status == http.StatusOK &&
+			!bytes.HasPrefix(body, []byte(`{"error"`)) {
+			responseCache.Set(key, encodeCachedResponse(rec.header, body),
+				ttl)
+		}
+
+		for name, values := range rec.header {
+			w.Header()[name] = values
+		}
+		w.Header().Set(cacheHeader, "MISS")
+		if rec.status != 0 {
+			w.WriteHeader(rec.status)
+		}
+		w.Write(body)
+	}
+}
+
+// GetCacheStats returns statistics of response cache usage
+func GetCacheStats(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	if responseCache == nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Response cache is disabled!"})
+		lgr.Error.Println("Request at /api/cache/stats failed, response " +
+			"cache is disabled!")
+		return
+	}
+
+	stats := responseCache.Stats()
+	lgr.Info.Println("Request at /api/cache/stats responding with Cache " +
+		"Stats!")
+	json.NewEncoder(w).Encode(responses.CacheStatsResponse{
+		CacheStats: &stats})
+}
@@ -0,0 +1,185 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	hdl "github.com/SimplyVC/oasis_api_server/src/handlers"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+)
+
+// countingHandler responds with body, counting how many times it was called
+func countingHandler(calls *int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Add("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}
+}
+
+func serveCached(h http.HandlerFunc, query string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/api/consensus/block?"+query, nil)
+	rr := httptest.NewRecorder()
+	hdl.Cached(h).ServeHTTP(rr, req)
+	return rr
+}
+
+func Test_Cached(t *testing.T) {
+	hdl.InitCache(1<<20, 0)
+
+	calls := 0
+	expected := `{"result":"Unicorn"}`
+	handler := countingHandler(&calls, expected)
+
+	serveCached(handler, "name=Oasis_Local&height=5")
+	rr := serveCached(handler, "height=5&name=Oasis_Local")
+
+	if calls != 1 {
+		t.Errorf("handler was called wrong number of times: got %v want %v",
+			calls, 1)
+	}
+	if cache := rr.Header().Get("X-Cache"); cache != "HIT" {
+		t.Errorf("handler returned wrong cache header: got %v want %v",
+			cache, "HIT")
+	}
+	if strings.TrimSpace(rr.Body.String()) != expected {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_Cached_Headers(t *testing.T) {
+	hdl.InitCache(1<<20, 0)
+
+	calls := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Add("Content-Type", "text/csv")
+		w.Header().Add("Content-Disposition",
+			`attachment; filename="unicorn.csv"`)
+		w.Write([]byte("height,unicorns\n5,1\n"))
+	}
+
+	serveCached(handler, "name=Oasis_Local&height=5")
+	rr := serveCached(handler, "name=Oasis_Local&height=5")
+
+	if calls != 1 {
+		t.Errorf("handler was called wrong number of times: got %v want %v",
+			calls, 1)
+	}
+	if contentType := rr.Header().Get("Content-Type"); contentType !=
+		"text/csv" {
+		t.Errorf("handler returned wrong content type: got %v want %v",
+			contentType, "text/csv")
+	}
+	expected := `attachment; filename="unicorn.csv"`
+	if disposition := rr.Header().Get("Content-Disposition"); disposition !=
+		expected {
+		t.Errorf("handler returned wrong content disposition: got %v "+
+			"want %v", disposition, expected)
+	}
+	if rr.Body.String() != "height,unicorns\n5,1\n" {
+		t.Errorf("handler returned unexpected body: got %v",
+			rr.Body.String())
+	}
+}
+
+func Test_Cached_Latest(t *testing.T) {
+	hdl.InitCache(1<<20, 0)
+
+	calls := 0
+	handler := countingHandler(&calls, `{"result":"Unicorn"}`)
+	serveCached(handler, "name=Oasis_Local")
+	serveCached(handler, "name=Oasis_Local")
+
+	if calls != 2 {
+		t.Errorf("handler was called wrong number of times: got %v want %v",
+			calls, 2)
+	}
+
+	hdl.InitCache(1<<20, time.Minute)
+	serveCached(handler, "name=Oasis_Local")
+	serveCached(handler, "name=Oasis_Local")
+
+	if calls != 3 {
+		t.Errorf("handler was called wrong number of times: got %v want %v",
+			calls, 3)
+	}
+}
+
+func Test_Cached_PinnedLatest(t *testing.T) {
+	hdl.InitCache(1<<20, 0)
+
+	calls := 0
+	handler := countingHandler(&calls, `{"result":"Unicorn"}`)
+	servePinned := func() {
+		req, _ := http.NewRequest("GET",
+			"/api/consensus/block?name=Oasis_Local&height=5", nil)
+		hdl.Cached(handler).ServeHTTP(httptest.NewRecorder(),
+			hdl.WithPinnedLatest(req))
+	}
+	servePinned()
+	servePinned()
+
+	if calls != 2 {
+		t.Errorf("handler was called wrong number of times: got %v want %v",
+			calls, 2)
+	}
+
+	hdl.InitCache(1<<20, time.Minute)
+	servePinned()
+	servePinned()
+
+	if calls != 3 {
+		t.Errorf("handler was called wrong number of times: got %v want %v",
+			calls, 3)
+	}
+}
+
+func Test_Cached_Error(t *testing.T) {
+	hdl.InitCache(1<<20, 0)
+
+	calls := 0
+	handler := countingHandler(&calls,
+		`{"error":"Node name requested doesn't exist"}`)
+	serveCached(handler, "name=Unicorn&height=5")
+	serveCached(handler, "name=Unicorn&height=5")
+
+	if calls != 2 {
+		t.Errorf("handler was called wrong number of times: got %v want %v",
+			calls, 2)
+	}
+}
+
+func Test_GetCacheStats(t *testing.T) {
+	hdl.InitCache(1<<20, 0)
+
+	calls := 0
+	handler := countingHandler(&calls, `{"result":"Unicorn"}`)
+	serveCached(handler, "name=Oasis_Local&height=5")
+	serveCached(handler, "name=Oasis_Local&height=5")
+
+	req, _ := http.NewRequest("GET", "/api/cache/stats", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(hdl.GetCacheStats).ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	cacheStats := &responses.CacheStatsResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), cacheStats)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	stats := cacheStats.CacheStats
+	if stats == nil || stats.Hits != 1 || stats.Misses != 1 ||
+		stats.Entries != 1 {
+		t.Errorf("handler returned unexpected stats: got %+v", stats)
+	}
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+// errRateChangeInterval is returned instead of validating a commission
+// schedule against rules without a rate change interval, as steps can't be
+// aligned with it
+var errRateChangeInterval = errors.New("commission rate change interval " +
+	"is zero")
+
+// copyCommissionSchedule returns copy of schedule which can be pruned and
+// amended without changing steps of schedule
+func copyCommissionSchedule(
+	schedule *staking.CommissionSchedule) *staking.CommissionSchedule {
+
+	rates := append([]staking.CommissionRateStep{}, schedule.Rates...)
+	bounds := append([]staking.CommissionRateBoundStep{}, schedule.Bounds...)
+	return &staking.CommissionSchedule{Rates: rates, Bounds: bounds}
+}
+
+// commissionSteps returns rate and bound steps of a pruned schedule as
+// percentages, estimating when each step starts
+func commissionSteps(clock *epochClock,
+	schedule *staking.CommissionSchedule) ([]*responses.CommissionRateStep,
+	[]*responses.CommissionBoundStep) {
+
+	rates := []*responses.CommissionRateStep{}
+	for i := range schedule.Rates {
+		step := &schedule.Rates[i]
+		rates = append(rates, &responses.CommissionRateStep{
+			Start:         step.Start,
+			EstimatedTime: clock.epochStart(step.Start),
+			Current:       step.Start <= clock.epoch,
+			Rate:          step.Rate,
+			RatePercent: percentage(&step.Rate,
+				staking.CommissionRateDenominator),
+		})
+	}
+
+	bounds := []*responses.CommissionBoundStep{}
+	for i := range schedule.Bounds {
+		step := &schedule.Bounds[i]
+		bounds = append(bounds, &responses.CommissionBoundStep{
+			Start:         step.Start,
+			EstimatedTime: clock.epochStart(step.Start),
+			Current:       step.Start <= clock.epoch,
+			RateMin:       step.RateMin,
+			RateMinPercent: percentage(&step.RateMin,
+				staking.CommissionRateDenominator),
+			RateMax: step.RateMax,
+			RateMaxPercent: percentage(&step.RateMax,
+				staking.CommissionRateDenominator),
+		})
+	}
+	return rates, bounds
+}
+
+// commissionSchedule describes commission schedule of escrow account at
+// address from epoch at height of clock on, checking it and amendment, if
+// given, against commission rules in force at that height.
+func commissionSchedule(ctx context.Context, so staking.Backend,
+	clock *epochClock, address staking.Address,
+	amendment *staking.CommissionSchedule) (*responses.CommissionSchedule,
+	error) {
+
+	params, err := so.ConsensusParameters(ctx, clock.height)
+	if err != nil {
+		return nil, err
+	}
+	account, err := accountAt(ctx, so, address, clock.height)
+	if err != nil {
+		return nil, err
+	}
+	rules := &params.CommissionScheduleRules
+
+	// Steps which ended before current epoch are left out
+	schedule := copyCommissionSchedule(&account.Escrow.CommissionSchedule)
+	schedule.Prune(clock.epoch)
+
+	result := &responses.CommissionSchedule{
+		Address:       address,
+		CurrentEpoch:  clock.epoch,
+		EpochInterval: clock.interval,
+		BlockTime:     clock.blockTime.Seconds(),
+		Rules:         *rules,
+		Valid:         true,
+	}
+	result.Rates, result.Bounds = commissionSteps(clock, schedule)
+	if rate := schedule.CurrentRate(clock.epoch); rate != nil {
+		current := percentage(rate, staking.CommissionRateDenominator)
+		result.CurrentRate = &current
+	}
+	if len(result.Bounds) > 0 && result.Bounds[0].Current {
+		result.CurrentBound = result.Bounds[0]
+	}
+
+	// Rules may have changed since schedule was last amended
+	err = errRateChangeInterval
+	if rules.RateChangeInterval > 0 {
+		err = copyCommissionSchedule(schedule).PruneAndValidateForGenesis(
+			rules, clock.epoch)
+	}
+	if err != nil {
+		result.Valid = false
+		result.Violation = err.Error()
+	}
+
+	if amendment != nil {
+		amended := copyCommissionSchedule(schedule)
+		err = errRateChangeInterval
+		if rules.RateChangeInterval > 0 {
+			err = amended.AmendAndPruneAndValidate(amendment, rules,
+				clock.epoch)
+		}
+
+		result.Amendment = &responses.CommissionAmendment{Valid: err == nil}
+		if err != nil {
+			result.Amendment.Violation = err.Error()
+		}
+		result.Amendment.Rates, result.Amendment.Bounds = commissionSteps(
+			clock, amended)
+	}
+	return result, nil
+}
+
+// GetCommissionSchedule returns commission schedule of an escrow account
+// with rates as percentages and estimated times of scheduled steps, and
+// whether it or a pending amendment violates commission rules.
+func GetCommissionSchedule(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving name of node from query request
+	nodeName := r.URL.Query().Get("name")
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Node name requested doesn't exist"})
+		return
+	}
+
+	// Retrieving height from query request
+	recvHeight := r.URL.Query().Get("height")
+	height := checkHeight(recvHeight)
+	if height == -1 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, height needs to be a " +
+				"string representing an int!"})
+		return
+	}
+
+	// Retrieving address of escrow account from query request
+	var address staking.Address
+	err := address.UnmarshalText([]byte(r.URL.Query().Get("address")))
+	if err != nil {
+		lgr.Error.Println("Failed to UnmarshalText into Address", err)
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to UnmarshalText into Address."})
+		return
+	}
+
+	// Retrieving pending amendment from query request
+	var amendment *staking.CommissionSchedule
+	recvRates := r.URL.Query().Get("rates")
+	recvBounds := r.URL.Query().Get("bounds")
+	if len(recvRates) > 0 || len(recvBounds) > 0 {
+		var msg string
+		amendment, msg = checkCommissionSchedule(recvRates, recvBounds)
+		if len(msg) > 0 {
+			json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+			return
+		}
+	}
+
+	// Attempt to load connection with consensus client
+	connection, co := loadConsensusClient(socket)
+
+	// Close connection once code underneath executes
+	defer connection.Close()
+
+	// If null object was retrieved send response
+	if co == nil {
+
+		// Stop code here faild to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to establish connection using socket: " +
+				socket})
+		return
+	}
+
+	ctx := context.Background()
+	clock, err := loadEpochClock(ctx, co, height)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Epoch Timing!"})
+
+		lgr.Error.Println("Request at /api/staking/commissionschedule "+
+			"failed to retrieve Epoch Timing : ", err)
+		return
+	}
+
+	schedule, err := commissionSchedule(ctx, co.Staking(), clock, address,
+		amendment)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Commission Schedule!"})
+
+		lgr.Error.Println("Request at /api/staking/commissionschedule "+
+			"failed to retrieve Commission Schedule : ", err)
+		return
+	}
+
+	lgr.Info.Println("Request at /api/staking/commissionschedule " +
+		"responding with Commission Schedule!")
+	json.NewEncoder(w).Encode(responses.CommissionScheduleResponse{
+		Height: height, CommissionSchedule: schedule})
+}
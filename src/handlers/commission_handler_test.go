@@ -0,0 +1,81 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+
+	hdl "github.com/SimplyVC/oasis_api_server/src/handlers"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+)
+
+func Test_GetCommissionSchedule_BadNode(t *testing.T) {
+	rr := serveRequest(hdl.GetCommissionSchedule,
+		"/api/staking/commissionschedule",
+		map[string]string{"name": "Unicorn", "address": builderAddress})
+	checkErrorResponse(t, rr, `{"error":"Node name requested doesn't exist"}`)
+}
+
+func Test_GetCommissionSchedule_InvalidHeight(t *testing.T) {
+	rr := serveRequest(hdl.GetCommissionSchedule,
+		"/api/staking/commissionschedule",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"height": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, height `+
+		`needs to be a string representing an int!"}`)
+}
+
+func Test_GetCommissionSchedule_InvalidAddress(t *testing.T) {
+	rr := serveRequest(hdl.GetCommissionSchedule,
+		"/api/staking/commissionschedule",
+		map[string]string{"name": "Oasis_Local", "address": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Failed to UnmarshalText into `+
+		`Address."}`)
+}
+
+func Test_GetCommissionSchedule_InvalidBounds(t *testing.T) {
+	rr := serveRequest(hdl.GetCommissionSchedule,
+		"/api/staking/commissionschedule",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"bounds": "5000:0"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, bounds `+
+		`needs to be a comma separated list of start:rate_min:rate_max `+
+		`steps!"}`)
+}
+
+func Test_GetCommissionSchedule(t *testing.T) {
+	rr := serveRequest(hdl.GetCommissionSchedule,
+		"/api/staking/commissionschedule",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"rates": "5000:10000", "bounds": "5000:0:20000"})
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	schedule := &responses.CommissionScheduleResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), schedule)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	result := schedule.CommissionSchedule
+	if result == nil || result.Amendment == nil {
+		t.Fatalf("handler returned unexpected body: got %v",
+			rr.Body.String())
+	}
+
+	// Rates are in units of 1/100000 and steps are ordered by start
+	for i, step := range result.Amendment.Rates {
+		percent := float64(step.Rate.ToBigInt().Int64()) / 1000
+		if math.Abs(step.RatePercent-percent) > 1e-9 {
+			t.Errorf("handler returned wrong rate percent: got %v want %v",
+				step.RatePercent, percent)
+		}
+		if i > 0 && step.Start <= result.Amendment.Rates[i-1].Start {
+			t.Errorf("handler returned unordered rates: got %v after %v",
+				step.Start, result.Amendment.Rates[i-1].Start)
+		}
+	}
+}
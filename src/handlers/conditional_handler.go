@@ -0,0 +1,417 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+)
+
+const (
+	// Header carrying height at which response was read
+	heightHeader = "X-Oasis-Height"
+
+	// Cache-Control of responses at an explicit height, which never change
+	immutableCacheControl = "public, max-age=31536000, immutable"
+
+	// Cache-Control of responses at latest height
+	latestCacheControl = "public, max-age=5"
+)
+
+// Messages of times outside of blocks retained by node
+const (
+	msgTimeAfterLatest = "Unexpected value found, time is after latest " +
+		"block!"
+	msgTimeBeforeEarliest = "Unexpected value found, time is before " +
+		"earliest block available!"
+)
+
+var (
+	// Chain contexts of nodes, by socket, used to tag responses
+	chainContexts      = make(map[string]string)
+	chainContextsMutex sync.RWMutex
+)
+
+// getChainContext returns chain context of node at socket, asking node
+// only the first time it is needed.
+func getChainContext(socket string) (string, error) {
+	chainContextsMutex.RLock()
+	chainContext, ok := chainContexts[socket]
+	chainContextsMutex.RUnlock()
+	if ok {
+		return chainContext, nil
+	}
+
+	connection, co := loadConsensusClient(socket)
+	if co == nil {
+		return "", fmt.Errorf("failed to establish connection using "+
+			"socket: %s", socket)
+	}
+	defer connection.Close()
+
+	chainContext, err := co.GetChainContext(context.Background())
+	if err != nil {
+		return "", err
+	}
+
+	chainContextsMutex.Lock()
+	chainContexts[socket] = chainContext
+	chainContextsMutex.Unlock()
+	return chainContext, nil
+}
+
+// resolveLatest returns latest height of node at socket, remembering its
+// chain context on the way
+func resolveLatest(socket string) (int64, error) {
+	connection, co := loadConsensusClient(socket)
+	if co == nil {
+		return 0, fmt.Errorf("failed to establish connection using "+
+			"socket: %s", socket)
+	}
+	defer connection.Close()
+
+	status, err := co.GetStatus(context.Background())
+	if err != nil {
+		return 0, err
+	}
+
+	chainContextsMutex.Lock()
+	chainContexts[socket] = status.ChainContext
+	chainContextsMutex.Unlock()
+	return status.LatestHeight, nil
+}
+
+// resolveTime returns height of last block produced at or before given
+// time, found by binary search over blocks retained by node at socket.
+func resolveTime(socket string, at time.Time) (int64, string, error) {
+	connection, co := loadConsensusClient(socket)
+	if co == nil {
+		return 0, "", fmt.Errorf("failed to establish connection using "+
+			"socket: %s", socket)
+	}
+	defer connection.Close()
+
+	status, err := co.GetStatus(context.Background())
+	if err != nil {
+		return 0, "", err
+	}
+	if at.After(status.LatestTime) {
+		return 0, msgTimeAfterLatest, nil
+	}
+
+	// Search blocks for which time of lowest one is at or before time
+	low := status.LastRetainedHeight
+	if low < status.GenesisHeight {
+		low = status.GenesisHeight
+	}
+	if low < 1 {
+		low = 1
+	}
+	high := status.LatestHeight
+
+	lowBlock, err := co.GetBlock(context.Background(), low)
+	if err != nil {
+		return 0, "", err
+	}
+	if at.Before(lowBlock.Time) {
+		return 0, msgTimeBeforeEarliest, nil
+	}
+
+	height, err := searchTime(context.Background(), co, at, low, high)
+	if err != nil {
+		return 0, "", err
+	}
+	return height, "", nil
+}
+
+// searchTime returns height of last block produced at or before given time
+// between heights low and high, found by binary search. Block at low must
+// have been produced at or before time.
+func searchTime(ctx context.Context, co consensus.ClientBackend,
+	at time.Time, low int64, high int64) (int64, error) {
+
+	for low < high {
+		mid := low + (high-low+1)/2
+		blk, err := co.GetBlock(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		if blk.Time.After(at) {
+			high = mid - 1
+		} else {
+			low = mid
+		}
+	}
+	return low, nil
+}
+
+// resolveEpoch returns height of first block of epoch
+func resolveEpoch(socket string, epoch beacon.EpochTime) (int64, string,
+	error) {
+
+	connection, co := loadConsensusClient(socket)
+	if co == nil {
+		return 0, "", fmt.Errorf("failed to establish connection using "+
+			"socket: %s", socket)
+	}
+	defer connection.Close()
+
+	status, err := co.GetStatus(context.Background())
+	if err != nil {
+		return 0, "", err
+	}
+	if epoch > status.LatestEpoch {
+		return 0, "Unexpected value found, epoch is after latest epoch!",
+			nil
+	}
+
+	height, err := co.Beacon().GetEpochBlock(context.Background(), epoch)
+	if err != nil {
+		return 0, "", err
+	}
+	return height, "", nil
+}
+
+// pinTimeOrEpoch resolves time or epoch requested to a height. Like
+// pinHeight it returns an error message if they can't be resolved.
+func pinTimeOrEpoch(r *http.Request, socket string) (int64, string) {
+	q := r.URL.Query()
+	given := 0
+	for _, param := range []string{"height", "time", "epoch"} {
+		if len(q.Get(param)) > 0 {
+			given++
+		}
+	}
+	if given > 1 {
+		return 0, "Unexpected value found, only one of height, time and " +
+			"epoch can be given!"
+	}
+
+	var height int64
+	var msg string
+	var err error
+	if recvTime := q.Get("time"); len(recvTime) > 0 {
+		at, errParse := time.Parse(time.RFC3339, recvTime)
+		if errParse != nil {
+			return 0, "Unexpected value found, time needs to be in " +
+				"RFC 3339 format!"
+		}
+		height, msg, err = resolveTime(socket, at)
+	} else {
+		epoch, errParse := strconv.ParseUint(q.Get("epoch"), 10, 64)
+		if errParse != nil {
+			return 0, "Unexpected value found, epoch needs to be " +
+				"a string representing an int!"
+		}
+		height, msg, err = resolveEpoch(socket, beacon.EpochTime(epoch))
+	}
+	if err != nil {
+		lgr.Error.Println("Request at "+r.URL.Path+" failed to "+
+			"resolve height : ", err)
+		return 0, "Failed to resolve height of time or epoch!"
+	}
+	return height, msg
+}
+
+// withHeight returns copy of request reading data at given height
+func withHeight(r *http.Request, height int64) *http.Request {
+	r = r.Clone(r.Context())
+	q := r.URL.Query()
+	q.Del("time")
+	q.Del("epoch")
+	q.Set("height", strconv.FormatInt(height, 10))
+	r.URL.RawQuery = q.Encode()
+	return r
+}
+
+// Key of request context value telling that request was pinned to a height
+// relative to latest height
+type pinnedLatestKey struct{}
+
+// withPinnedLatest returns copy of request marked as pinned to a height
+// relative to latest height
+func withPinnedLatest(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), pinnedLatestKey{},
+		true))
+}
+
+// pinnedFromLatest checks whether request was pinned to a height relative
+// to latest height
+func pinnedFromLatest(r *http.Request) bool {
+	pinned, _ := r.Context().Value(pinnedLatestKey{}).(bool)
+	return pinned
+}
+
+// pinHeight resolves height of request to a concrete height, rewriting
+// request so that handler reads data at that height. It returns rewritten
+// request, socket of node, resolved height and whether it is relative to
+// latest height, in which case rewritten request is marked as such. Height
+// is 0 when request is left to handler to validate, while an error message
+// is returned if a height selector can't be resolved.
+func pinHeight(r *http.Request) (*http.Request, string, int64, bool,
+	string) {
+
+	confirmation, socket := checkNodeName(r.URL.Query().Get("name"))
+	if !confirmation {
+		return r, socket, 0, false, ""
+	}
+
+	// Time and epoch are resolved to a height which never changes
+	if len(r.URL.Query().Get("time")) > 0 ||
+		len(r.URL.Query().Get("epoch")) > 0 {
+
+		height, msg := pinTimeOrEpoch(r, socket)
+		if len(msg) > 0 {
+			return r, socket, 0, false, msg
+		}
+		return withHeight(r, height), socket, height, false, ""
+	}
+
+	recvHeight := r.URL.Query().Get("height")
+	offset, relative := checkHeightSelector(recvHeight)
+	if !relative {
+		height := checkHeight(recvHeight)
+		if height == -1 {
+			return r, socket, 0, false, ""
+		}
+		if height != consensus.HeightLatest {
+			return r, socket, height, false, ""
+		}
+	}
+
+	latest, err := resolveLatest(socket)
+	if err != nil {
+		lgr.Error.Println("Request at "+r.URL.Path+" failed to "+
+			"resolve latest height : ", err)
+
+		// Without a selector handler can still read latest height
+		if len(recvHeight) == 0 {
+			return r, socket, 0, true, ""
+		}
+		return r, socket, 0, true, "Failed to resolve height " +
+			recvHeight + "!"
+	}
+
+	height := latest - offset
+	if height < 1 {
+		return r, socket, 0, true, "Unexpected value found, height " +
+			recvHeight + " is below first block!"
+	}
+
+	return withPinnedLatest(withHeight(r, height)), socket, height, true, ""
+}
+
+// Pinned wraps handler of a height based endpoint so that latest height and
+// height selectors are resolved once, and height read is returned in the
+// X-Oasis-Height header.
+func Pinned(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r, _, height, _, msg := pinHeight(r)
+		if len(msg) > 0 {
+			w.Header().Add("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+			return
+		}
+
+		if height > 0 {
+			w.Header().Set(heightHeader, strconv.FormatInt(height, 10))
+		}
+		h(w, r)
+	}
+}
+
+// computeETag returns strong ETag of response to request for path with
+// given query, served by node with given chain context.
+func computeETag(chainContext string, path string, query string) string {
+	sum := sha256.Sum256([]byte(chainContext + "\n" + path + "\n" + query))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches checks whether If-None-Match header matches ETag. Wildcard
+// isn't treated as a match, as it only matches if request would succeed,
+// which isn't known before handler runs.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == etag ||
+			strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Conditional wraps handler of a height based endpoint so its responses can
+// be cached by clients. Requests are pinned to a concrete height as done by
+// Pinned, and responses carry a strong ETag derived from
+// node's chain context, height and query parameters. Requests whose
+// If-None-Match header matches the ETag are answered with 304 Not Modified.
+func Conditional(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r, socket, height, latest, msg := pinHeight(r)
+		if len(msg) > 0 {
+			w.Header().Add("Content-Type", "application/json")
+			w.Header().Set("Cache-Control", "no-store")
+			json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+			return
+		}
+
+		// Invalid requests are left to handler to reply to
+		if height == 0 {
+			h(w, r)
+			return
+		}
+
+		chainContext, err := getChainContext(socket)
+		if err != nil {
+			lgr.Error.Println("Request at "+r.URL.Path+" failed to "+
+				"retrieve chain context : ", err)
+			h(w, r)
+			return
+		}
+
+		cacheControl := immutableCacheControl
+		if latest {
+			cacheControl = latestCacheControl
+		}
+
+		etag := computeETag(chainContext, r.URL.Path, r.URL.Query().Encode())
+		header := w.Header()
+		header.Set(heightHeader, strconv.FormatInt(height, 10))
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			header.Set("ETag", etag)
+			header.Set("Cache-Control", cacheControl)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		rec := &cacheRecorder{ResponseWriter: w}
+		h(rec, r)
+
+		// Errors such as failing to reach node must not be cached
+		body := rec.body.Bytes()
+		if bytes.HasPrefix(body, []byte(`{"error"`)) {
+			header.Del(heightHeader)
+			header.Set("Cache-Control", "no-store")
+		} else {
+			header.Set("ETag", etag)
+			header.Set("Cache-Control", cacheControl)
+		}
+		if rec.status != 0 {
+			w.WriteHeader(rec.status)
+		}
+		w.Write(body)
+	}
+}
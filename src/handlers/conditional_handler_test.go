@@ -0,0 +1,232 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	hdl "github.com/SimplyVC/oasis_api_server/src/handlers"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+)
+
+func serveConditional(h http.HandlerFunc, query string,
+	ifNoneMatch string) *httptest.ResponseRecorder {
+
+	req, _ := http.NewRequest("GET", "/api/consensus/block?"+query, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	rr := httptest.NewRecorder()
+	hdl.Conditional(h).ServeHTTP(rr, req)
+	return rr
+}
+
+func Test_Conditional_BadNode(t *testing.T) {
+	rr := serveConditional(hdl.GetBlock, "name=Unicorn&height=5", "")
+
+	expected := `{"error":"Node name requested doesn't exist"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+	if etag := rr.Header().Get("ETag"); etag != "" {
+		t.Errorf("handler returned ETag for error: got %v", etag)
+	}
+}
+
+func Test_Conditional_InvalidHeight(t *testing.T) {
+	rr := serveConditional(hdl.GetBlock, "name=Oasis_Local&height=Unicorn",
+		"")
+
+	expected := `{"error":"Unexpected value found, height needs to be a string representing an int!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+	if etag := rr.Header().Get("ETag"); etag != "" {
+		t.Errorf("handler returned ETag for error: got %v", etag)
+	}
+}
+
+func Test_Conditional(t *testing.T) {
+	rr := serveConditional(hdl.GetBlock, "name=Oasis_Local&height=1", "")
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	etag := rr.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("handler returned no ETag")
+	}
+	if cacheControl := rr.Header().Get("Cache-Control"); !strings.Contains(
+		cacheControl, "immutable") {
+		t.Errorf("handler returned wrong Cache-Control: got %v",
+			cacheControl)
+	}
+	if height := rr.Header().Get("X-Oasis-Height"); height != "1" {
+		t.Errorf("handler returned wrong height: got %v want %v",
+			height, "1")
+	}
+
+	rr = serveConditional(hdl.GetBlock, "name=Oasis_Local&height=1", etag)
+	if status := rr.Code; status != http.StatusNotModified {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusNotModified)
+	}
+}
+
+func Test_Conditional_Wildcard(t *testing.T) {
+	rr := serveConditional(hdl.GetAccount,
+		"name=Oasis_Local&height=1&address=Unicorn", "*")
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Failed to UnmarshalText into Address."}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_Conditional_Latest(t *testing.T) {
+	rr := serveConditional(hdl.GetBlock, "name=Oasis_Local", "")
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	if height := rr.Header().Get("X-Oasis-Height"); height == "" {
+		t.Errorf("handler returned no height")
+	}
+	if cacheControl := rr.Header().Get("Cache-Control"); cacheControl !=
+		"public, max-age=5" {
+		t.Errorf("handler returned wrong Cache-Control: got %v want %v",
+			cacheControl, "public, max-age=5")
+	}
+}
+
+func servePinned(h http.HandlerFunc, query string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/api/consensus/block?"+query, nil)
+	rr := httptest.NewRecorder()
+	hdl.Pinned(h).ServeHTTP(rr, req)
+	return rr
+}
+
+func Test_Pinned_InvalidHeight(t *testing.T) {
+	rr := servePinned(hdl.GetBlock, "name=Oasis_Local&height=latest-Unicorn")
+
+	expected := `{"error":"Unexpected value found, height needs to be a string representing an int!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_Pinned_BelowFirstBlock(t *testing.T) {
+	rr := servePinned(hdl.GetBlock,
+		"name=Oasis_Local&height=latest-1000000000000")
+
+	expected := `{"error":"Unexpected value found, height latest-1000000000000 is below first block!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_Pinned_Finalized(t *testing.T) {
+	rr := servePinned(hdl.GetBlock, "name=Oasis_Local&height=finalized")
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	block := &responses.BlockResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), block)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	height := rr.Header().Get("X-Oasis-Height")
+	if block.Blk == nil || strconv.FormatInt(block.Height, 10) != height ||
+		block.Blk.Height != block.Height {
+		t.Errorf("handler returned unexpected height: got %v want %v",
+			block.Height, height)
+	}
+}
+
+func Test_Pinned_InvalidTime(t *testing.T) {
+	rr := servePinned(hdl.GetBlock, "name=Oasis_Local&time=Unicorn")
+
+	expected := `{"error":"Unexpected value found, time needs to be in RFC 3339 format!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_Pinned_InvalidEpoch(t *testing.T) {
+	rr := servePinned(hdl.GetBlock, "name=Oasis_Local&epoch=Unicorn")
+
+	expected := `{"error":"Unexpected value found, epoch needs to be a string representing an int!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_Pinned_TimeAndEpoch(t *testing.T) {
+	rr := servePinned(hdl.GetBlock,
+		"name=Oasis_Local&time=2021-05-03T00:00:00Z&epoch=1")
+
+	expected := `{"error":"Unexpected value found, only one of height, time and epoch can be given!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_Pinned_FutureTime(t *testing.T) {
+	rr := servePinned(hdl.GetBlock,
+		"name=Oasis_Local&time=2999-01-01T00:00:00Z")
+
+	expected := `{"error":"Unexpected value found, time is after latest block!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_Pinned_Epoch(t *testing.T) {
+	rr := servePinned(hdl.GetEpoch, "name=Oasis_Local&epoch=1")
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	epoch := &responses.EpochResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), epoch)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	if epoch.Ep != 1 || epoch.Height <= 0 {
+		t.Errorf("handler returned unexpected epoch: got %v at height %v "+
+			"want %v", epoch.Ep, epoch.Height, 1)
+	}
+}
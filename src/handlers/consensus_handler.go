@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"google.golang.org/grpc"
 
@@ -12,9 +13,12 @@ import (
 	"github.com/SimplyVC/oasis_api_server/src/rpc"
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
 	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	results_api "github.com/oasisprotocol/oasis-core/go/consensus/api/transaction/results"
 	mint_api "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
 	"github.com/oasisprotocol/oasis-core/go/consensus/tendermint/crypto"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
 )
 
 // loadConsensusClient loads consensus client and returns it
@@ -61,6 +65,15 @@ func GetConsensusStateToGenesis(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Retrieving delivery options of response from query request
+	opts, msg := checkStreamOptions(r)
+	if len(msg) > 0 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
 	// Attempt to load connection with consensus client
 	connection, co := loadConsensusClient(socket)
 
@@ -91,8 +104,10 @@ func GetConsensusStateToGenesis(w http.ResponseWriter, r *http.Request) {
 	// Responding with consensus genesis state object, retrieved above.
 	lgr.Info.Println("Request at /api/consensus/genesis responding with" +
 		" genesis file!")
-	json.NewEncoder(w).Encode(responses.ConsensusGenesisResponse{
-		GenJSON: consensusGenesis})
+	streamJSON(w, "/api/consensus/genesis",
+		"consensus_genesis_"+streamHeightName(height)+".json", opts,
+		responses.ConsensusGenesisResponse{Height: height,
+			GenJSON: consensusGenesis})
 }
 
 // GetEpoch returns current epoch of given block height
@@ -156,7 +171,8 @@ func GetEpoch(w http.ResponseWriter, r *http.Request) {
 	// Respond with retrieved epoch above
 	lgr.Info.Println("Request at /api/consensus/epoch responding" +
 		" with an Epoch!")
-	json.NewEncoder(w).Encode(responses.EpochResponse{Ep: epoch})
+	json.NewEncoder(w).Encode(responses.EpochResponse{
+		Height: height, Ep: epoch})
 }
 
 // PingNode returns consensus block at specific height
@@ -273,7 +289,8 @@ func GetBlock(w http.ResponseWriter, r *http.Request) {
 
 	// Responding with retrieved block
 	lgr.Info.Println("Request at /api/consensus/block responding with Block!")
-	json.NewEncoder(w).Encode(responses.BlockResponse{Blk: blk})
+	json.NewEncoder(w).Encode(responses.BlockResponse{
+		Height: height, Blk: blk})
 }
 
 // GetStatus returns the current status overview.
@@ -343,6 +360,15 @@ func GetGenesisDocument(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Retrieving delivery options of response from query request
+	opts, msg := checkStreamOptions(r)
+	if len(msg) > 0 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
 	// Attempt to load connection with consensus client
 	connection, co := loadConsensusClient(socket)
 
@@ -374,8 +400,9 @@ func GetGenesisDocument(w http.ResponseWriter, r *http.Request) {
 	lgr.Info.Println(
 		"Request at /api/consensus/genesisdocument responding with Genesis " +
 		"Document!")
-	json.NewEncoder(w).Encode(responses.GenesisDocumentResponse{GenesisDocument: 
-		genesisDocument})
+	streamJSON(w, "/api/consensus/genesisdocument",
+		"genesis_document.json", opts,
+		responses.GenesisDocumentResponse{GenesisDocument: genesisDocument})
 }
 
 // GetBlockHeader returns consensus block header at specific height
@@ -449,7 +476,7 @@ func GetBlockHeader(w http.ResponseWriter, r *http.Request) {
 	lgr.Info.Println("Request at /api/consensus/blockheader responding " +
 		"with Block Header!")
 	json.NewEncoder(w).Encode(responses.BlockHeaderResponse{
-		BlkHeader: meta.Header})
+		Height: height, BlkHeader: meta.Header})
 }
 
 // GetBlockLastCommit returns consensus block last commit at specific height
@@ -521,7 +548,7 @@ func GetBlockLastCommit(w http.ResponseWriter, r *http.Request) {
 	lgr.Info.Println("Request at /api/consensus/blocklastcommit " +
 		"responding with Block Last Commit!")
 	json.NewEncoder(w).Encode(responses.BlockLastCommitResponse{
-		BlkLastCommit: meta.LastCommit})
+		Height: height, BlkLastCommit: meta.LastCommit})
 }
 
 // PublicKeyToAddress accepts a Consensus Public Key and respond with
@@ -589,6 +616,21 @@ func GetTransactions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Retrieving whether transactions should be decoded from query
+	decoded := false
+	if recvDecoded := r.URL.Query().Get("decoded"); len(recvDecoded) > 0 {
+		var err error
+		decoded, err = strconv.ParseBool(recvDecoded)
+		if err != nil {
+
+			// Stop code here no need to establish connection and reply
+			json.NewEncoder(w).Encode(responses.ErrorResponse{
+				Error: "Unexpected value found, decoded needs to be " +
+					"either true or false!"})
+			return
+		}
+	}
+
 	// Attempt to load connection with consensus client
 	connection, co := loadConsensusClient(socket)
 
@@ -617,9 +659,252 @@ func GetTransactions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if decoded {
+
+		// Chain context is needed to verify signatures of transactions
+		chainContext, err := getChainContext(socket)
+		if err != nil {
+			json.NewEncoder(w).Encode(responses.ErrorResponse{
+				Error: "Failed to retrieve Chain Context!"})
+
+			lgr.Error.Println("Request at /api/consensus/transactions "+
+				"failed to retrieve Chain Context : ", err)
+			return
+		}
+
+		decodedTransactions := make([]*responses.DecodedTransaction, 0,
+			len(transactions))
+		for _, raw := range transactions {
+			decodedTransactions = append(decodedTransactions,
+				decodeTransaction(chainContext, raw))
+		}
+
+		// Responds with transactions decoded above
+		lgr.Info.Println("Request at /api/consensus/transactions " +
+			"responding with all decoded transactions in specified Block!")
+		json.NewEncoder(w).Encode(responses.DecodedTransactionsResponse{
+			Height: height, Transactions: decodedTransactions})
+		return
+	}
+
 	// Responds with transactions retrieved above
 	lgr.Info.Println("Request at /api/consensus/transactions responding" +
 		"with all transactions in specified Block!")
 	json.NewEncoder(w).Encode(responses.TransactionsResponse{
-		Transactions: transactions})
+		Height: height, Transactions: transactions})
+}
+
+// GetTransactionsWithResults returns decoded transactions of block at
+// specified height together with their results and emitted events.
+func GetTransactionsWithResults(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving name of node from query request
+	nodeName := r.URL.Query().Get("name")
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Node name requested doesn't exist"})
+		return
+	}
+
+	// Retrieving height from query
+	recvHeight := r.URL.Query().Get("height")
+	height := checkHeight(recvHeight)
+	if height == -1 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, height needs to be " +
+				"a string representing an int!"})
+		return
+	}
+
+	// Retrieving methods to filter transactions by from query
+	methods, err := checkTransactionMethods(r.URL.Query().Get("method"))
+	if err != nil {
+		lgr.Error.Println("Request at /api/consensus/transactionswithresults"+
+			" received unknown method : ", err)
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, method needs to be a comma " +
+				"separated list of transaction methods!"})
+		return
+	}
+
+	// Retrieving address to filter transactions by from query
+	var address staking.Address
+	filterAddress := false
+	if addressQuery := r.URL.Query().Get("address"); len(addressQuery) > 0 {
+		if err := address.UnmarshalText([]byte(addressQuery)); err != nil {
+			lgr.Error.Println("Failed to UnmarshalText into Address", err)
+			json.NewEncoder(w).Encode(responses.ErrorResponse{
+				Error: "Failed to UnmarshalText into Address."})
+			return
+		}
+		filterAddress = true
+	}
+
+	// Attempt to load connection with consensus client
+	connection, co := loadConsensusClient(socket)
+
+	// Close connection once code underneath executes
+	defer connection.Close()
+
+	// If null object was retrieved send response
+	if co == nil {
+
+		// Stop code here faild to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to establish connection using socket: " +
+				socket})
+		return
+	}
+
+	// Use consensus client to retrieve transactions at specific block
+	// height together with their results
+	txsWithResults, err := co.GetTransactionsWithResults(
+		context.Background(), height)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Transactions With Results!"})
+
+		lgr.Error.Println("Request at /api/consensus/transactionswithresults"+
+			" failed to retrieve Transactions With Results : ", err)
+		return
+	}
+
+	// Chain context is needed to verify signatures of transactions
+	chainContext, err := getChainContext(socket)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Chain Context!"})
+
+		lgr.Error.Println("Request at /api/consensus/transactionswithresults"+
+			" failed to retrieve Chain Context : ", err)
+		return
+	}
+
+	// Pair each transaction with its result, keeping only those matching
+	// filters
+	transactions := []*responses.TransactionWithResult{}
+	for _, tx := range transactionsWithResults(chainContext, txsWithResults) {
+		if len(methods) > 0 && !methods[tx.Transaction.Method] {
+			continue
+		}
+		if filterAddress && !transactionInvolves(tx, address) {
+			continue
+		}
+		transactions = append(transactions, tx)
+	}
+
+	// Responds with transactions and results retrieved above
+	lgr.Info.Println("Request at /api/consensus/transactionswithresults " +
+		"responding with Transactions With Results!")
+	json.NewEncoder(w).Encode(responses.TransactionsWithResultsResponse{
+		Height: height, Transactions: transactions})
+}
+
+// SubmitTransaction submits signed transaction from body of request to node,
+// once signature is checked against node's chain context, and waits for it
+// to be included in a block.
+func SubmitTransaction(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving name of node from query request
+	nodeName := r.URL.Query().Get("name")
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Node name requested doesn't exist"})
+		return
+	}
+
+	// Retrieving signed transaction from body of request
+	signed, err := readSignedTransaction(r)
+	if err != nil {
+		lgr.Error.Println("Request at /api/consensus/submittx failed to "+
+			"decode Signed Transaction : ", err)
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to decode Signed Transaction!"})
+		return
+	}
+
+	// Chain context is needed to verify signature of transaction
+	chainContext, err := getChainContext(socket)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Chain Context!"})
+
+		lgr.Error.Println("Request at /api/consensus/submittx failed to "+
+			"retrieve Chain Context : ", err)
+		return
+	}
+
+	// Reject transactions which node would reject anyway
+	decoded := decodeTransaction(chainContext, cbor.Marshal(signed))
+	if len(decoded.Error) > 0 {
+		lgr.Error.Println("Request at /api/consensus/submittx received "+
+			"invalid Transaction : ", decoded.Error)
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to decode Transaction!"})
+		return
+	}
+	if !decoded.ValidSignature {
+		lgr.Error.Println("Request at /api/consensus/submittx received " +
+			"Transaction with invalid signature!")
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Transaction signature is invalid for chain of node!"})
+		return
+	}
+
+	// Attempt to load connection with consensus client
+	connection, co := loadConsensusClient(socket)
+
+	// Close connection once code underneath executes
+	defer connection.Close()
+
+	// If null object was retrieved send response
+	if co == nil {
+
+		// Stop code here faild to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to establish connection using socket: " +
+				socket})
+		return
+	}
+
+	// Submit transaction and wait for it to be included in a block
+	submitted := &responses.SubmittedTransaction{Hash: decoded.Hash}
+	err = co.SubmitTx(r.Context(), signed)
+	if err != nil {
+		module, code := errors.Code(err)
+		if module == errors.UnknownModule {
+			json.NewEncoder(w).Encode(responses.ErrorResponse{
+				Error: "Failed to submit Transaction!"})
+
+			lgr.Error.Println("Request at /api/consensus/submittx failed "+
+				"to submit Transaction : ", err)
+			return
+		}
+
+		// Transaction was rejected by node or failed to execute
+		submitted.Error = &results_api.Error{
+			Module: module, Code: code, Message: err.Error()}
+	} else {
+		submitted.Success = true
+	}
+
+	// Responds with hash and result of transaction submitted above
+	lgr.Info.Println("Request at /api/consensus/submittx responding with " +
+		"Submitted Transaction " + decoded.Hash.String() + "!")
+	json.NewEncoder(w).Encode(responses.SubmittedTransactionResponse{
+		SubmittedTransaction: submitted})
 }
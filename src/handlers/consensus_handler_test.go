@@ -1,7 +1,11 @@
 package handlers_test
 
 import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -117,6 +121,74 @@ func Test_GetConsensusStateToGenesis(t *testing.T) {
 	}
 }
 
+func Test_GetConsensusStateToGenesis_InvalidGzip(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/consensus/genesis", nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	q.Add("gzip", "Unicorn")
+
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetConsensusStateToGenesis)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Unexpected value found, gzip needs to be either true or false!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_GetConsensusStateToGenesis_Download(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/consensus/genesis", nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	q.Add("height", "1")
+	q.Add("gzip", "true")
+	q.Add("download", "true")
+
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetConsensusStateToGenesis)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	resp := rr.Result()
+	expected := `attachment; filename="consensus_genesis_1.json"`
+
+	if disposition := resp.Header.Get("Content-Disposition"); disposition !=
+		expected {
+		t.Errorf("handler returned wrong content disposition: got %v "+
+			"want %v", disposition, expected)
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read gzipped body : %v", err)
+	}
+	body, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("Failed to read gzipped body : %v", err)
+	}
+
+	sum := sha256.Sum256(body)
+	if hash := resp.Trailer.Get("X-Content-SHA256"); hash !=
+		hex.EncodeToString(sum[:]) {
+		t.Errorf("handler returned wrong content hash: got %v want %v",
+			hash, hex.EncodeToString(sum[:]))
+	}
+}
+
 func Test_GetConsensusStateToGenesis_Heightn2(t *testing.T) {
 	req, _ := http.NewRequest("GET", "/api/consensus/genesis", nil)
 	q := req.URL.Query()
@@ -496,3 +568,310 @@ func Test_GetTransactions_InvalidHeight(t *testing.T) {
 			rr.Body.String(), expected)
 	}
 }
+
+func Test_GetTransactions_InvalidDecoded(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/consensus/transactions", nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	q.Add("decoded", "Unicorn")
+
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetTransactions)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Unexpected value found, decoded needs to be either true or false!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_GetTransactions_Decoded(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/consensus/transactions", nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	q.Add("decoded", "true")
+
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetTransactions)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	transactions := &responses.DecodedTransactionsResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), transactions)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	if transactions.Transactions == nil {
+		t.Errorf("handler returned unexpected body: got %v",
+			rr.Body.String())
+	}
+	for _, tx := range transactions.Transactions {
+		if !tx.ValidSignature || tx.Error != "" {
+			t.Errorf("handler returned invalid transaction: got %+v", tx)
+		}
+	}
+}
+
+func Test_GetTransactionsWithResults_BadNode(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/consensus/transactionswithresults",
+		nil)
+	q := req.URL.Query()
+	q.Add("name", "Unicorn")
+
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetTransactionsWithResults)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Node name requested doesn't exist"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_GetTransactionsWithResults_InvalidHeight(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/consensus/transactionswithresults",
+		nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	q.Add("height", "Unicorn")
+
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetTransactionsWithResults)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Unexpected value found, height needs to be a string representing an int!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_GetTransactionsWithResults_InvalidMethod(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/consensus/transactionswithresults",
+		nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	q.Add("method", "staking.Unicorn")
+
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetTransactionsWithResults)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Unexpected value found, method needs to be a comma separated list of transaction methods!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_GetTransactionsWithResults_MethodWithoutBody(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/consensus/transactionswithresults",
+		nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	q.Add("method", "registry.DeregisterEntity")
+	q.Add("address", "Unicorn")
+
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetTransactionsWithResults)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	// Method is accepted so request fails on address instead
+	expected := `{"error":"Failed to UnmarshalText into Address."}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_GetTransactionsWithResults_InvalidAddress(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/consensus/transactionswithresults",
+		nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	q.Add("address", "Unicorn")
+
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetTransactionsWithResults)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Failed to UnmarshalText into Address."}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_GetTransactionsWithResults(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/consensus/transactionswithresults",
+		nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetTransactionsWithResults)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	transactions := &responses.TransactionsWithResultsResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), transactions)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	if transactions.Transactions == nil {
+		t.Errorf("handler returned unexpected body: got %v",
+			rr.Body.String())
+	}
+}
+
+func Test_SubmitTransaction_BadNode(t *testing.T) {
+	req, _ := http.NewRequest("POST", "/api/consensus/submittx",
+		strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	q := req.URL.Query()
+	q.Add("name", "Unicorn")
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.SubmitTransaction)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Node name requested doesn't exist"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_SubmitTransaction_InvalidJSON(t *testing.T) {
+	req, _ := http.NewRequest("POST", "/api/consensus/submittx",
+		strings.NewReader(`Unicorn`))
+	req.Header.Set("Content-Type", "application/json")
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.SubmitTransaction)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Failed to decode Signed Transaction!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_SubmitTransaction_InvalidCBOR(t *testing.T) {
+	req, _ := http.NewRequest("POST", "/api/consensus/submittx",
+		strings.NewReader(`Unicorn`))
+	req.Header.Set("Content-Type", "application/cbor")
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.SubmitTransaction)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Failed to decode Signed Transaction!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_SubmitTransaction_EmptyTransaction(t *testing.T) {
+	req, _ := http.NewRequest("POST", "/api/consensus/submittx",
+		strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.SubmitTransaction)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Failed to decode Signed Transaction!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
@@ -0,0 +1,493 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+// Directions of delegations valued
+const (
+	// Delegations to an escrow account from its delegators
+	directionTo = "to"
+
+	// Delegations of a delegator to escrow accounts
+	directionFor = "for"
+)
+
+// pooledShares is a delegation or debonding delegation together with share
+// pool its shares are in
+type pooledShares struct {
+	delegator     staking.Address
+	validator     staking.Address
+	shares        quantity.Quantity
+	pool          *staking.SharePool
+	debondEndTime *beacon.EpochTime
+}
+
+// delegationsTo returns active and debonding delegations to escrow account
+// of address, whose shares are in pools of that account
+func delegationsTo(ctx context.Context, so staking.Backend,
+	address staking.Address, height int64) (active []*pooledShares,
+	debonding []*pooledShares, err error) {
+
+	account, err := accountAt(ctx, so, address, height)
+	if err != nil {
+		return nil, nil, err
+	}
+	query := staking.OwnerQuery{Height: height, Owner: address}
+
+	delegations, err := so.DelegationsTo(ctx, &query)
+	if err != nil {
+		return nil, nil, err
+	}
+	for delegator, delegation := range delegations {
+		active = append(active, &pooledShares{delegator: delegator,
+			validator: address, shares: delegation.Shares,
+			pool: &account.Escrow.Active})
+	}
+
+	debondingDelegations, err := so.DebondingDelegationsTo(ctx, &query)
+	if err != nil {
+		return nil, nil, err
+	}
+	for delegator, list := range debondingDelegations {
+		for _, delegation := range list {
+			debondEndTime := delegation.DebondEndTime
+			debonding = append(debonding, &pooledShares{
+				delegator: delegator, validator: address,
+				shares: delegation.Shares, pool: &account.Escrow.Debonding,
+				debondEndTime: &debondEndTime})
+		}
+	}
+	return active, debonding, nil
+}
+
+// delegationsFor returns active and debonding delegations of delegator at
+// address, whose shares are in pools of each escrow account
+func delegationsFor(ctx context.Context, so staking.Backend,
+	address staking.Address, height int64) (active []*pooledShares,
+	debonding []*pooledShares, err error) {
+
+	query := staking.OwnerQuery{Height: height, Owner: address}
+
+	delegations, err := so.DelegationInfosFor(ctx, &query)
+	if err != nil {
+		return nil, nil, err
+	}
+	for validator, info := range delegations {
+		active = append(active, &pooledShares{delegator: address,
+			validator: validator, shares: info.Shares, pool: &info.Pool})
+	}
+
+	debondingDelegations, err := so.DebondingDelegationInfosFor(ctx, &query)
+	if err != nil {
+		return nil, nil, err
+	}
+	for validator, list := range debondingDelegations {
+		for _, info := range list {
+			debondEndTime := info.DebondEndTime
+			debonding = append(debonding, &pooledShares{delegator: address,
+				validator: validator, shares: info.Shares, pool: &info.Pool,
+				debondEndTime: &debondEndTime})
+		}
+	}
+	return active, debonding, nil
+}
+
+// valueDelegations converts shares of delegations to base units and tokens,
+// ordered by counterpart of address and then by end of debonding.
+func valueDelegations(units tokenUnits, direction string,
+	delegations []*pooledShares) ([]*responses.DelegationValue, error) {
+
+	sort.Slice(delegations, func(i, j int) bool {
+		a, b := delegations[i], delegations[j]
+		if c := bytes.Compare(counterpart(direction, a),
+			counterpart(direction, b)); c != 0 {
+			return c < 0
+		}
+		return a.debondEndTime != nil && b.debondEndTime != nil &&
+			*a.debondEndTime < *b.debondEndTime
+	})
+
+	values := []*responses.DelegationValue{}
+	for _, delegation := range delegations {
+		amount, err := delegation.pool.StakeForShares(&delegation.shares)
+		if err != nil {
+			return nil, err
+		}
+		share := percentage(&delegation.shares,
+			&delegation.pool.TotalShares)
+
+		values = append(values, &responses.DelegationValue{
+			Delegator:      delegation.delegator,
+			Validator:      delegation.validator,
+			Shares:         delegation.shares,
+			Amount:         *amount,
+			Tokens:         units.format(amount),
+			PoolPercentage: share,
+			DebondEndTime:  delegation.debondEndTime,
+		})
+	}
+	return values, nil
+}
+
+// counterpart returns address on other side of delegation from address
+// requested, as bytes for ordering
+func counterpart(direction string, delegation *pooledShares) []byte {
+	address := delegation.delegator
+	if direction == directionFor {
+		address = delegation.validator
+	}
+	raw, _ := address.MarshalBinary()
+	return raw
+}
+
+// totalDelegations sums valued delegations per counterpart of address,
+// ordered by largest total first, and in total
+func totalDelegations(units tokenUnits, direction string,
+	address staking.Address, active []*responses.DelegationValue,
+	debonding []*responses.DelegationValue) ([]*responses.DelegationTotal,
+	*responses.DelegationTotal, error) {
+
+	totals := make(map[staking.Address]*responses.DelegationTotal)
+	total := &responses.DelegationTotal{Address: address}
+
+	add := func(value *responses.DelegationValue, isDebonding bool) error {
+		other := value.Delegator
+		if direction == directionFor {
+			other = value.Validator
+		}
+		if totals[other] == nil {
+			totals[other] = &responses.DelegationTotal{Address: other}
+		}
+
+		for _, sum := range []*responses.DelegationTotal{totals[other],
+			total} {
+			pool := &sum.Active
+			if isDebonding {
+				pool = &sum.Debonding
+			}
+			if err := pool.Add(&value.Amount); err != nil {
+				return err
+			}
+			if err := sum.Total.Add(&value.Amount); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, value := range active {
+		if err := add(value, false); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, value := range debonding {
+		if err := add(value, true); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	list := []*responses.DelegationTotal{}
+	for _, sum := range totals {
+		list = append(list, sum)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if c := list[i].Total.Cmp(&list[j].Total); c != 0 {
+			return c > 0
+		}
+		return list[i].Address.String() < list[j].Address.String()
+	})
+
+	for _, sum := range append(list, total) {
+		sum.ActiveTokens = units.format(&sum.Active)
+		sum.DebondingTokens = units.format(&sum.Debonding)
+		sum.TotalTokens = units.format(&sum.Total)
+	}
+	return list, total, nil
+}
+
+// GetDelegationValues returns active and debonding delegations to or from
+// an account with their shares converted to base units and tokens, their
+// percentage of share pool and totals per counterpart.
+func GetDelegationValues(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving name of node from query request
+	nodeName := r.URL.Query().Get("name")
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Node name requested doesn't exist"})
+		return
+	}
+
+	// Retrieving height from query request
+	recvHeight := r.URL.Query().Get("height")
+	height := checkHeight(recvHeight)
+	if height == -1 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, height needs to be a " +
+				"string representing an int!"})
+		return
+	}
+
+	// Retrieving address from query request
+	var address staking.Address
+	err := address.UnmarshalText([]byte(r.URL.Query().Get("address")))
+	if err != nil {
+		lgr.Error.Println("Failed to UnmarshalText into Address", err)
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to UnmarshalText into Address."})
+		return
+	}
+
+	// Retrieving direction of delegations from query request
+	direction := r.URL.Query().Get("direction")
+	if len(direction) == 0 {
+		direction = directionTo
+	}
+	if direction != directionTo && direction != directionFor {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, direction needs to be " +
+				"either to or for!"})
+		return
+	}
+
+	// Attempt to load connection with staking client
+	connection, so := loadStakingClient(socket)
+
+	// Close connection once code underneath executes
+	defer connection.Close()
+
+	// If null object was retrieved send response
+	if so == nil {
+
+		// Stop code here faild to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to establish connection using socket : " +
+				socket})
+		return
+	}
+
+	ctx := context.Background()
+	units, err := getTokenUnits(ctx, so)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Token Units!"})
+
+		lgr.Error.Println("Request at /api/staking/delegationvalues "+
+			"failed to retrieve Token Units : ", err)
+		return
+	}
+
+	var active, debonding []*pooledShares
+	if direction == directionTo {
+		active, debonding, err = delegationsTo(ctx, so, address, height)
+	} else {
+		active, debonding, err = delegationsFor(ctx, so, address, height)
+	}
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to get Delegations!"})
+
+		lgr.Error.Println("Request at /api/staking/delegationvalues "+
+			"failed to retrieve Delegations : ", err)
+		return
+	}
+
+	values := &responses.DelegationValues{TokenSymbol: units.symbol}
+	values.Active, err = valueDelegations(units, direction, active)
+	if err == nil {
+		values.Debonding, err = valueDelegations(units, direction,
+			debonding)
+	}
+	if err == nil {
+		values.Totals, values.Total, err = totalDelegations(units,
+			direction, address, values.Active, values.Debonding)
+	}
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to value Delegations!"})
+
+		lgr.Error.Println("Request at /api/staking/delegationvalues "+
+			"failed to value Delegations : ", err)
+		return
+	}
+
+	lgr.Info.Println("Request at /api/staking/delegationvalues " +
+		"responding with Delegation Values!")
+	json.NewEncoder(w).Encode(responses.DelegationValuesResponse{
+		Height: height, DelegationValues: values})
+}
+
+// debondingSchedule groups valued debonding delegations by epoch at which
+// they are released, estimating when each epoch starts. Delegations are
+// released automatically at the start of their epoch, so all epochs are
+// yet to come.
+func debondingSchedule(units tokenUnits, clock *epochClock,
+	debonding []*responses.DelegationValue) (*responses.DebondingSchedule,
+	error) {
+
+	schedule := &responses.DebondingSchedule{
+		TokenSymbol:   units.symbol,
+		CurrentEpoch:  clock.epoch,
+		EpochInterval: clock.interval,
+		BlockTime:     clock.blockTime.Seconds(),
+		Releases:      []*responses.DebondingRelease{},
+	}
+
+	releases := make(map[beacon.EpochTime]*responses.DebondingRelease)
+	for _, value := range debonding {
+		epoch := *value.DebondEndTime
+		release := releases[epoch]
+		if release == nil {
+			release = &responses.DebondingRelease{
+				Epoch:         epoch,
+				EstimatedTime: clock.epochStart(epoch),
+			}
+			releases[epoch] = release
+			schedule.Releases = append(schedule.Releases, release)
+		}
+		release.Delegations = append(release.Delegations, value)
+
+		if err := release.Amount.Add(&value.Amount); err != nil {
+			return nil, err
+		}
+		if err := schedule.Total.Add(&value.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(schedule.Releases, func(i, j int) bool {
+		return schedule.Releases[i].Epoch < schedule.Releases[j].Epoch
+	})
+	for _, release := range schedule.Releases {
+		release.Tokens = units.format(&release.Amount)
+	}
+	schedule.TotalTokens = units.format(&schedule.Total)
+	return schedule, nil
+}
+
+// GetDebondingSchedule returns debonding delegations of a delegator grouped
+// by epoch at which they are released, with estimated time of release.
+func GetDebondingSchedule(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving name of node from query request
+	nodeName := r.URL.Query().Get("name")
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Node name requested doesn't exist"})
+		return
+	}
+
+	// Retrieving height from query request
+	recvHeight := r.URL.Query().Get("height")
+	height := checkHeight(recvHeight)
+	if height == -1 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, height needs to be a " +
+				"string representing an int!"})
+		return
+	}
+
+	// Retrieving address from query request
+	var address staking.Address
+	err := address.UnmarshalText([]byte(r.URL.Query().Get("address")))
+	if err != nil {
+		lgr.Error.Println("Failed to UnmarshalText into Address", err)
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to UnmarshalText into Address."})
+		return
+	}
+
+	// Attempt to load connection with consensus client
+	connection, co := loadConsensusClient(socket)
+
+	// Close connection once code underneath executes
+	defer connection.Close()
+
+	// If null object was retrieved send response
+	if co == nil {
+
+		// Stop code here faild to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to establish connection using socket: " +
+				socket})
+		return
+	}
+
+	ctx := context.Background()
+	clock, err := loadEpochClock(ctx, co, height)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Epoch Timing!"})
+
+		lgr.Error.Println("Request at /api/staking/debondingschedule "+
+			"failed to retrieve Epoch Timing : ", err)
+		return
+	}
+
+	units, err := getTokenUnits(ctx, co.Staking())
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Token Units!"})
+
+		lgr.Error.Println("Request at /api/staking/debondingschedule "+
+			"failed to retrieve Token Units : ", err)
+		return
+	}
+
+	_, debonding, err := delegationsFor(ctx, co.Staking(), address,
+		clock.height)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to get Debonding Delegations!"})
+
+		lgr.Error.Println("Request at /api/staking/debondingschedule "+
+			"failed to retrieve Debonding Delegations : ", err)
+		return
+	}
+
+	values, err := valueDelegations(units, directionFor, debonding)
+	var schedule *responses.DebondingSchedule
+	if err == nil {
+		schedule, err = debondingSchedule(units, clock, values)
+	}
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to value Debonding Delegations!"})
+
+		lgr.Error.Println("Request at /api/staking/debondingschedule "+
+			"failed to value Debonding Delegations : ", err)
+		return
+	}
+
+	lgr.Info.Println("Request at /api/staking/debondingschedule " +
+		"responding with Debonding Schedule!")
+	json.NewEncoder(w).Encode(responses.DebondingScheduleResponse{
+		Height: height, DebondingSchedule: schedule})
+}
@@ -0,0 +1,141 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	hdl "github.com/SimplyVC/oasis_api_server/src/handlers"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+)
+
+func Test_GetDelegationValues_BadNode(t *testing.T) {
+	rr := serveRequest(hdl.GetDelegationValues,
+		"/api/staking/delegationvalues",
+		map[string]string{"name": "Unicorn", "address": builderAddress})
+	checkErrorResponse(t, rr, `{"error":"Node name requested doesn't exist"}`)
+}
+
+func Test_GetDelegationValues_InvalidHeight(t *testing.T) {
+	rr := serveRequest(hdl.GetDelegationValues,
+		"/api/staking/delegationvalues",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"height": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, height `+
+		`needs to be a string representing an int!"}`)
+}
+
+func Test_GetDelegationValues_InvalidAddress(t *testing.T) {
+	rr := serveRequest(hdl.GetDelegationValues,
+		"/api/staking/delegationvalues",
+		map[string]string{"name": "Oasis_Local", "address": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Failed to UnmarshalText into `+
+		`Address."}`)
+}
+
+func Test_GetDelegationValues_InvalidDirection(t *testing.T) {
+	rr := serveRequest(hdl.GetDelegationValues,
+		"/api/staking/delegationvalues",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"direction": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, direction `+
+		`needs to be either to or for!"}`)
+}
+
+func Test_GetDelegationValues(t *testing.T) {
+	for _, direction := range []string{"to", "for"} {
+		rr := serveRequest(hdl.GetDelegationValues,
+			"/api/staking/delegationvalues",
+			map[string]string{"name": "Oasis_Local",
+				"address": builderAddress, "direction": direction})
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v",
+				status, http.StatusOK)
+		}
+
+		values := &responses.DelegationValuesResponse{}
+		err := json.Unmarshal([]byte(rr.Body.String()), values)
+		if err != nil {
+			t.Fatalf("Failed to unmarshall data")
+		}
+
+		if values.DelegationValues == nil ||
+			values.DelegationValues.Total == nil {
+			t.Fatalf("handler returned unexpected body: got %v",
+				rr.Body.String())
+		}
+
+		// Total of each counterparty and overall is active plus debonding
+		totals := append(values.DelegationValues.Totals,
+			values.DelegationValues.Total)
+		for _, total := range totals {
+			sum := total.Active.Clone()
+			if err := sum.Add(&total.Debonding); err != nil ||
+				sum.Cmp(&total.Total) != 0 {
+				t.Errorf("handler returned wrong total: got %v want %v",
+					total.Total, sum)
+			}
+		}
+	}
+}
+
+func Test_GetDebondingSchedule_BadNode(t *testing.T) {
+	rr := serveRequest(hdl.GetDebondingSchedule,
+		"/api/staking/debondingschedule",
+		map[string]string{"name": "Unicorn", "address": builderAddress})
+	checkErrorResponse(t, rr, `{"error":"Node name requested doesn't exist"}`)
+}
+
+func Test_GetDebondingSchedule_InvalidHeight(t *testing.T) {
+	rr := serveRequest(hdl.GetDebondingSchedule,
+		"/api/staking/debondingschedule",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"height": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, height `+
+		`needs to be a string representing an int!"}`)
+}
+
+func Test_GetDebondingSchedule_InvalidAddress(t *testing.T) {
+	rr := serveRequest(hdl.GetDebondingSchedule,
+		"/api/staking/debondingschedule",
+		map[string]string{"name": "Oasis_Local", "address": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Failed to UnmarshalText into `+
+		`Address."}`)
+}
+
+func Test_GetDebondingSchedule(t *testing.T) {
+	rr := serveRequest(hdl.GetDebondingSchedule,
+		"/api/staking/debondingschedule",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress})
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	schedule := &responses.DebondingScheduleResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), schedule)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	result := schedule.DebondingSchedule
+	if result == nil || result.EpochInterval <= 0 {
+		t.Fatalf("handler returned unexpected body: got %v",
+			rr.Body.String())
+	}
+
+	// Releases are all yet to come and add up to total
+	total := quantity.NewQuantity()
+	for _, release := range result.Releases {
+		if release.Epoch <= result.CurrentEpoch {
+			t.Errorf("handler returned past release: got epoch %v at "+
+				"epoch %v", release.Epoch, result.CurrentEpoch)
+		}
+		total.Add(&release.Amount)
+	}
+	if total.Cmp(&result.Total) != 0 {
+		t.Errorf("handler returned wrong total: got %v want %v",
+			result.Total, total)
+	}
+}
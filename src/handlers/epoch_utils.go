@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+)
+
+// Number of blocks over which average block time is observed
+const blockTimeSample = 100
+
+// errEpochDuration is returned when too few blocks were observed to estimate
+// duration of an epoch
+var errEpochDuration = errors.New("epoch duration can't be estimated")
+
+// epochClock estimates when epochs start, from epoch interval set in beacon
+// parameters and block times observed before a height
+type epochClock struct {
+	epoch       beacon.EpochTime
+	epochHeight int64
+	interval    int64
+	height      int64
+	time        time.Time
+	blockTime   time.Duration
+}
+
+// epochInterval returns number of blocks in an epoch
+func epochInterval(params *beacon.ConsensusParameters) (int64, error) {
+	switch {
+	case params.InsecureParameters != nil &&
+		params.InsecureParameters.Interval > 0:
+		return params.InsecureParameters.Interval, nil
+	case params.PVSSParameters != nil:
+		pvss := params.PVSSParameters
+		return pvss.CommitInterval + pvss.RevealInterval +
+			pvss.TransitionDelay, nil
+	}
+	return 0, errors.New("beacon parameters have no epoch interval")
+}
+
+// loadEpochClock reads epoch at height together with epoch interval and
+// average time between blocks leading up to height
+func loadEpochClock(ctx context.Context, co consensus.ClientBackend,
+	height int64) (*epochClock, error) {
+
+	blk, err := co.GetBlock(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	clock := &epochClock{height: blk.Height, time: blk.Time}
+
+	params, err := co.Beacon().ConsensusParameters(ctx, clock.height)
+	if err != nil {
+		return nil, err
+	}
+	if clock.interval, err = epochInterval(params); err != nil {
+		return nil, err
+	}
+
+	if clock.epoch, err = co.Beacon().GetEpoch(ctx,
+		clock.height); err != nil {
+		return nil, err
+	}
+	if clock.epochHeight, err = co.Beacon().GetEpochBlock(ctx,
+		clock.epoch); err != nil {
+		return nil, err
+	}
+
+	// Observe only blocks node still retains
+	status, err := co.GetStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	earliest := clock.height - blockTimeSample
+	if earliest < status.LastRetainedHeight {
+		earliest = status.LastRetainedHeight
+	}
+	if earliest < 1 {
+		earliest = 1
+	}
+	if earliest < clock.height {
+		first, err := co.GetBlock(ctx, earliest)
+		if err != nil {
+			return nil, err
+		}
+		clock.blockTime = clock.time.Sub(first.Time) /
+			time.Duration(clock.height-earliest)
+	}
+	return clock, nil
+}
+
+// epochDuration returns estimated duration of an epoch
+func (c *epochClock) epochDuration() time.Duration {
+	return c.blockTime * time.Duration(c.interval)
+}
+
+// epochStart returns estimated time at which epoch starts, or started if it
+// is in the past
+func (c *epochClock) epochStart(epoch beacon.EpochTime) time.Time {
+	epochs := int64(epoch) - int64(c.epoch)
+	height := c.epochHeight + epochs*c.interval
+	return c.time.Add(c.blockTime * time.Duration(height-c.height))
+}
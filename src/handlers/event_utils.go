@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	common_signature "github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+// Kinds of staking events that can be filtered on
+var stakingEventKinds = []string{"transfer", "burn", "add_escrow",
+	"take_escrow", "reclaim_escrow", "allowance_change"}
+
+// Kinds of registry events that can be filtered on
+var registryEventKinds = []string{"entity", "node", "runtime",
+	"node_unfrozen"}
+
+// eventID identifies an event by its height and its index among all events
+// emitted at that height.
+type eventID struct {
+	height int64
+	index  int
+}
+
+// String formats event ID as height-index
+func (id eventID) String() string {
+	return fmt.Sprintf("%d-%d", id.height, id.index)
+}
+
+// parseEventID parses event ID in the form of height-index
+func parseEventID(recvID string) (eventID, error) {
+	parts := strings.Split(recvID, "-")
+	if len(parts) != 2 {
+		return eventID{}, fmt.Errorf("malformed event ID %s", recvID)
+	}
+
+	height, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || height <= 0 {
+		return eventID{}, fmt.Errorf("malformed event height %s", parts[0])
+	}
+	index, err := strconv.Atoi(parts[1])
+	if err != nil || index < 0 {
+		return eventID{}, fmt.Errorf("malformed event index %s", parts[1])
+	}
+	return eventID{height: height, index: index}, nil
+}
+
+// checkEventKinds parses comma separated list of event kinds, checking that
+// each one is known. An empty list matches every kind.
+func checkEventKinds(recvKinds string, known []string) (map[string]bool,
+	error) {
+
+	kinds := make(map[string]bool)
+	if len(recvKinds) == 0 {
+		return kinds, nil
+	}
+
+	for _, kind := range strings.Split(recvKinds, ",") {
+		kind = strings.TrimSpace(kind)
+		found := false
+		for _, k := range known {
+			if kind == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown event kind %s", kind)
+		}
+		kinds[kind] = true
+	}
+	return kinds, nil
+}
+
+// stakingEventKind returns kind of given staking event
+func stakingEventKind(ev *staking.Event) string {
+	switch {
+	case ev.Transfer != nil:
+		return "transfer"
+	case ev.Burn != nil:
+		return "burn"
+	case ev.Escrow != nil && ev.Escrow.Add != nil:
+		return "add_escrow"
+	case ev.Escrow != nil && ev.Escrow.Take != nil:
+		return "take_escrow"
+	case ev.Escrow != nil && ev.Escrow.Reclaim != nil:
+		return "reclaim_escrow"
+	case ev.AllowanceChange != nil:
+		return "allowance_change"
+	}
+	return "unknown"
+}
+
+// stakingEventAccounts returns addresses of accounts taking part in staking
+// event
+func stakingEventAccounts(ev *staking.Event) []staking.Address {
+	switch {
+	case ev.Transfer != nil:
+		return []staking.Address{ev.Transfer.From, ev.Transfer.To}
+	case ev.Burn != nil:
+		return []staking.Address{ev.Burn.Owner}
+	case ev.Escrow != nil && ev.Escrow.Add != nil:
+		return []staking.Address{ev.Escrow.Add.Owner, ev.Escrow.Add.Escrow}
+	case ev.Escrow != nil && ev.Escrow.Take != nil:
+		return []staking.Address{ev.Escrow.Take.Owner}
+	case ev.Escrow != nil && ev.Escrow.Reclaim != nil:
+		return []staking.Address{ev.Escrow.Reclaim.Owner,
+			ev.Escrow.Reclaim.Escrow}
+	case ev.AllowanceChange != nil:
+		return []staking.Address{ev.AllowanceChange.Owner,
+			ev.AllowanceChange.Beneficiary}
+	}
+	return nil
+}
+
+// stakingEventInvolves checks whether address takes part in staking event
+func stakingEventInvolves(ev *staking.Event, address staking.Address) bool {
+	for _, account := range stakingEventAccounts(ev) {
+		if account.Equal(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// registryEventKind returns kind of given registry event
+func registryEventKind(ev *registry.Event) string {
+	switch {
+	case ev.EntityEvent != nil:
+		return "entity"
+	case ev.NodeEvent != nil:
+		return "node"
+	case ev.RuntimeEvent != nil:
+		return "runtime"
+	case ev.NodeUnfrozenEvent != nil:
+		return "node_unfrozen"
+	}
+	return "unknown"
+}
+
+// registryEventInvolves checks whether entity or node with given ID takes
+// part in registry event
+func registryEventInvolves(ev *registry.Event,
+	id common_signature.PublicKey) bool {
+
+	switch {
+	case ev.EntityEvent != nil && ev.EntityEvent.Entity != nil:
+		return ev.EntityEvent.Entity.ID.Equal(id)
+	case ev.NodeEvent != nil && ev.NodeEvent.Node != nil:
+		return ev.NodeEvent.Node.ID.Equal(id) ||
+			ev.NodeEvent.Node.EntityID.Equal(id)
+	case ev.RuntimeEvent != nil && ev.RuntimeEvent.Runtime != nil:
+		return ev.RuntimeEvent.Runtime.EntityID.Equal(id)
+	case ev.NodeUnfrozenEvent != nil:
+		return ev.NodeUnfrozenEvent.NodeID.Equal(id)
+	}
+	return false
+}
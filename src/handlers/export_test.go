@@ -0,0 +1,8 @@
+package handlers
+
+// Unexported functions used by external tests of handlers
+var (
+	WithPinnedLatest = withPinnedLatest
+	RealisedAmounts  = realisedAmounts
+	WindowBounds     = windowBounds
+)
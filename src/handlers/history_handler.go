@@ -0,0 +1,562 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SimplyVC/oasis_api_server/src/indexer"
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+const (
+	// Number of items in a page of history unless limit is given
+	defaultHistoryLimit = 100
+
+	// Largest number of items in a page of history
+	maxHistoryLimit = 1000
+
+	// Most heights scanned on node for a page of history which isn't
+	// indexed, after which the page ends early
+	maxHistoryScan = 500
+)
+
+// Kinds of staking events part of history of an account
+var historyEventKinds = map[string]bool{"transfer": true, "burn": true,
+	"add_escrow": true, "take_escrow": true, "reclaim_escrow": true}
+
+// Columns of history exported as CSV
+var historyColumns = []string{"height", "kind", "type", "tx_hash", "from",
+	"to", "amount", "fee", "success"}
+
+// historyCursor is position in history of an account, following staking
+// events and then transactions of each height. Kind is 'e' for events, 't'
+// for transactions and 'b' for end of block.
+type historyCursor struct {
+	height int64
+	kind   byte
+	index  int
+}
+
+// String formats cursor as height-kind-index
+func (c historyCursor) String() string {
+	return fmt.Sprintf("%d-%c-%d", c.height, c.kind, c.index)
+}
+
+// rank orders kinds of positions within a height
+func (c historyCursor) rank() int {
+	return strings.IndexByte("etb", c.kind)
+}
+
+// passed checks whether position at height of given kind and index comes at
+// or before cursor
+func (c *historyCursor) passed(height int64, kind byte, index int) bool {
+	if c == nil {
+		return false
+	}
+	pos := historyCursor{height: height, kind: kind, index: index}
+	switch {
+	case pos.height != c.height:
+		return pos.height < c.height
+	case pos.rank() != c.rank():
+		return pos.rank() < c.rank()
+	}
+	return pos.index <= c.index
+}
+
+// parseHistoryCursor parses cursor in the form of height-kind-index
+func parseHistoryCursor(recvCursor string) (*historyCursor, error) {
+	parts := strings.Split(recvCursor, "-")
+	if len(parts) != 3 || len(parts[1]) != 1 ||
+		!strings.Contains("etb", parts[1]) {
+		return nil, fmt.Errorf("malformed cursor %s", recvCursor)
+	}
+
+	height, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || height <= 0 {
+		return nil, fmt.Errorf("malformed cursor height %s", parts[0])
+	}
+	index, err := strconv.Atoi(parts[2])
+	if err != nil || index < 0 {
+		return nil, fmt.Errorf("malformed cursor index %s", parts[2])
+	}
+	return &historyCursor{height: height, kind: parts[1][0], index: index},
+		nil
+}
+
+// historyQuery holds inputs of a request for history of an account
+type historyQuery struct {
+	nodeName string
+	socket   string
+	address  staking.Address
+	from     int64
+	to       int64
+	after    *historyCursor
+	limit    int
+	csv      bool
+}
+
+// checkRangeBound parses bound of range of heights given as a height or a
+// time. Times are resolved to heights, the lowest height at or after time
+// for a lower bound and the highest height at or before time for an upper
+// bound. It returns 0 if bound isn't given or lies beyond blocks of node.
+func checkRangeBound(r *http.Request, socket string, bound string,
+	lower bool) (int64, string) {
+
+	recvHeight := r.URL.Query().Get(bound + "_height")
+	recvTime := r.URL.Query().Get(bound + "_time")
+	if len(recvHeight) > 0 && len(recvTime) > 0 {
+		return 0, "Unexpected value found, only one of " + bound +
+			"_height and " + bound + "_time can be given!"
+	}
+
+	if len(recvHeight) > 0 {
+		height, err := strconv.ParseInt(recvHeight, 10, 64)
+		if err != nil || height <= 0 {
+			return 0, "Unexpected value found, " + bound + "_height " +
+				"needs to be a string representing an int!"
+		}
+		return height, ""
+	}
+	if len(recvTime) == 0 {
+		return 0, ""
+	}
+
+	at, err := time.Parse(time.RFC3339, recvTime)
+	if err != nil {
+		return 0, "Unexpected value found, " + bound + "_time needs to " +
+			"be in RFC 3339 format!"
+	}
+
+	// First block at or after time follows last block before it
+	if lower {
+		at = at.Add(-time.Nanosecond)
+	}
+	height, msg, err := resolveTime(socket, at)
+	if err != nil {
+		lgr.Error.Println("Request at "+r.URL.Path+" failed to resolve "+
+			"height of time : ", err)
+		return 0, "Failed to resolve height of time!"
+	}
+	switch {
+	case msg == msgTimeBeforeEarliest && lower:
+		return 0, ""
+	case msg == msgTimeAfterLatest && !lower:
+		return 0, ""
+	case len(msg) > 0:
+		return 0, msg
+	case lower:
+		return height + 1, ""
+	}
+	return height, ""
+}
+
+// checkRange parses range of heights given by from_height or from_time
+// and to_height or to_time. Bounds which aren't given are returned as 0.
+func checkRange(r *http.Request, socket string) (int64, int64, string) {
+	from, msg := checkRangeBound(r, socket, "from", true)
+	if len(msg) > 0 {
+		return 0, 0, msg
+	}
+	to, msg := checkRangeBound(r, socket, "to", false)
+	if len(msg) > 0 {
+		return 0, 0, msg
+	}
+	if from > 0 && to > 0 && from > to {
+		return 0, 0, "Unexpected value found, start of range is after " +
+			"its end!"
+	}
+	return from, to, ""
+}
+
+// checkHistoryQuery parses inputs of a request for history of an account,
+// returning an error message if any of them is invalid.
+func checkHistoryQuery(r *http.Request) (*historyQuery, string) {
+	q := r.URL.Query()
+	query := &historyQuery{nodeName: q.Get("name"),
+		limit: defaultHistoryLimit}
+
+	confirmation, socket := checkNodeName(query.nodeName)
+	if !confirmation {
+		return nil, "Node name requested doesn't exist"
+	}
+	query.socket = socket
+
+	if err := query.address.UnmarshalText(
+		[]byte(q.Get("address"))); err != nil {
+		lgr.Error.Println("Failed to UnmarshalText into Address", err)
+		return nil, "Failed to UnmarshalText into Address."
+	}
+
+	if recvLimit := q.Get("limit"); len(recvLimit) > 0 {
+		limit, err := strconv.Atoi(recvLimit)
+		if err != nil || limit < 1 || limit > maxHistoryLimit {
+			return nil, "Unexpected value found, limit needs to be " +
+				"a number between 1 and " + strconv.Itoa(maxHistoryLimit) +
+				"!"
+		}
+		query.limit = limit
+	}
+
+	if recvCursor := q.Get("cursor"); len(recvCursor) > 0 {
+		after, err := parseHistoryCursor(recvCursor)
+		if err != nil {
+			return nil, "Unexpected value found, cursor needs to be " +
+				"the next cursor of a previous page!"
+		}
+		query.after = after
+	}
+
+	switch q.Get("format") {
+	case "", "json":
+	case "csv":
+		query.csv = true
+	default:
+		return nil, "Unexpected value found, format needs to be either " +
+			"json or csv!"
+	}
+
+	var msg string
+	if query.from, query.to, msg = checkRange(r, socket); len(msg) > 0 {
+		return nil, msg
+	}
+	return query, ""
+}
+
+// indexCovers checks whether index holds range of history requested,
+// setting bounds which aren't given to those of index.
+func indexCovers(query *historyQuery) bool {
+	if blockIndex == nil || query.nodeName != indexerNodeName {
+		return false
+	}
+
+	status, err := blockIndex.Status()
+	if err != nil || status.Checkpoint == 0 {
+		return false
+	}
+
+	from, to := query.from, query.to
+	if from == 0 {
+		from = status.FirstHeight
+	}
+	if to == 0 {
+		to = status.Checkpoint
+	}
+	if from < status.FirstHeight || to > status.Checkpoint {
+		return false
+	}
+
+	query.from, query.to = from, to
+	return true
+}
+
+// indexedHistory reads page of history of account from index
+func indexedHistory(query *historyQuery) ([]*responses.AccountHistoryItem,
+	string, error) {
+
+	history := []*responses.AccountHistoryItem{}
+
+	var after *indexer.AccountItem
+	if query.after != nil {
+		after = &indexer.AccountItem{Height: query.after.height,
+			Kind: indexer.ItemEvent, Index: query.after.index}
+		switch query.after.kind {
+		case 't':
+			after.Kind = indexer.ItemTransaction
+		case 'b':
+			after.Kind = indexer.ItemTransaction
+			after.Index = math.MaxUint32
+		}
+	}
+
+	for {
+		items, err := blockIndex.AccountItems(query.address, query.from,
+			query.to, after, query.limit)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(items) == 0 {
+			return history, "", nil
+		}
+
+		for i := range items {
+			item := &items[i]
+			after = item
+
+			historyItem := &responses.AccountHistoryItem{
+				Height: item.Height, Kind: item.Kind, Index: item.Index}
+			if item.Kind == indexer.ItemEvent {
+				ev, err := blockIndex.StakingEvent(item.Height, item.Index)
+				if err != nil {
+					return nil, "", err
+				}
+				historyItem.EventKind = stakingEventKind(ev)
+				if !historyEventKinds[historyItem.EventKind] {
+					continue
+				}
+				historyItem.Event = ev
+			} else {
+				tx, err := blockIndex.Transaction(item.Height, item.Index)
+				if err != nil {
+					return nil, "", err
+				}
+				if !tx.Transaction.SignerAddress.Equal(query.address) {
+					continue
+				}
+				historyItem.Transaction = tx
+			}
+
+			history = append(history, historyItem)
+			if len(history) == query.limit {
+				return history, historyItemCursor(historyItem).String(),
+					nil
+			}
+		}
+	}
+}
+
+// historyItemCursor returns cursor of item of history
+func historyItemCursor(item *responses.AccountHistoryItem) historyCursor {
+	cursor := historyCursor{height: item.Height, kind: 'e',
+		index: item.Index}
+	if item.Kind == indexer.ItemTransaction {
+		cursor.kind = 't'
+	}
+	return cursor
+}
+
+// liveHistory reads page of history of account by scanning blocks of node.
+// At most maxHistoryScan heights are scanned, after which page ends early
+// with a cursor following last height scanned.
+func liveHistory(query *historyQuery, co consensus.ClientBackend,
+	chainContext string) ([]*responses.AccountHistoryItem, string, error) {
+
+	ctx := context.Background()
+	history := []*responses.AccountHistoryItem{}
+
+	start := query.from
+	if query.after != nil && query.after.height > start {
+		start = query.after.height
+	}
+
+	for height := start; height <= query.to; height++ {
+		if height-start >= maxHistoryScan {
+			return history, historyCursor{height: height - 1,
+				kind: 'b'}.String(), nil
+		}
+
+		events, err := co.Staking().GetEvents(ctx, height)
+		if err != nil {
+			return nil, "", err
+		}
+		for i, ev := range events {
+			if query.after.passed(height, 'e', i) {
+				continue
+			}
+			kind := stakingEventKind(ev)
+			if !historyEventKinds[kind] ||
+				!stakingEventInvolves(ev, query.address) {
+				continue
+			}
+
+			history = append(history, &responses.AccountHistoryItem{
+				Height: height, Kind: indexer.ItemEvent, Index: i,
+				EventKind: kind, Event: ev})
+			if len(history) == query.limit {
+				return history, historyCursor{height: height, kind: 'e',
+					index: i}.String(), nil
+			}
+		}
+
+		txsWithResults, err := co.GetTransactionsWithResults(ctx, height)
+		if err != nil {
+			return nil, "", err
+		}
+		txs := transactionsWithResults(chainContext, txsWithResults)
+		for i, tx := range txs {
+			if query.after.passed(height, 't', i) ||
+				!tx.Transaction.SignerAddress.Equal(query.address) {
+				continue
+			}
+
+			history = append(history, &responses.AccountHistoryItem{
+				Height: height, Kind: indexer.ItemTransaction, Index: i,
+				Transaction: tx})
+			if len(history) == query.limit {
+				return history, historyCursor{height: height, kind: 't',
+					index: i}.String(), nil
+			}
+		}
+	}
+	return history, "", nil
+}
+
+// bodyField returns first of named fields found in transaction body, which
+// is either decoded into its type or read back from index as a map
+func bodyField(body interface{}, names ...string) string {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return ""
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return ""
+	}
+	for _, name := range names {
+		if value, ok := fields[name]; ok {
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
+// historyRecord returns CSV record of item of history
+func historyRecord(item *responses.AccountHistoryItem) []string {
+	record := []string{strconv.FormatInt(item.Height, 10), item.Kind, "",
+		"", "", "", "", "", ""}
+
+	if ev := item.Event; ev != nil {
+		record[2] = item.EventKind
+		record[3] = ev.TxHash.String()
+		switch {
+		case ev.Transfer != nil:
+			record[4] = ev.Transfer.From.String()
+			record[5] = ev.Transfer.To.String()
+			record[6] = ev.Transfer.Amount.String()
+		case ev.Burn != nil:
+			record[4] = ev.Burn.Owner.String()
+			record[6] = ev.Burn.Amount.String()
+		case ev.Escrow != nil && ev.Escrow.Add != nil:
+			record[4] = ev.Escrow.Add.Owner.String()
+			record[5] = ev.Escrow.Add.Escrow.String()
+			record[6] = ev.Escrow.Add.Amount.String()
+		case ev.Escrow != nil && ev.Escrow.Take != nil:
+			record[4] = ev.Escrow.Take.Owner.String()
+			record[6] = ev.Escrow.Take.Amount.String()
+		case ev.Escrow != nil && ev.Escrow.Reclaim != nil:
+			record[4] = ev.Escrow.Reclaim.Escrow.String()
+			record[5] = ev.Escrow.Reclaim.Owner.String()
+			record[6] = ev.Escrow.Reclaim.Amount.String()
+		}
+		return record
+	}
+
+	tx := item.Transaction
+	record[2] = string(tx.Transaction.Method)
+	record[3] = tx.Transaction.Hash.String()
+	record[4] = tx.Transaction.SignerAddress.String()
+	record[5] = bodyField(tx.Transaction.Body, "to", "account",
+		"beneficiary", "from")
+	record[6] = bodyField(tx.Transaction.Body, "amount", "shares",
+		"amount_change")
+	if tx.Transaction.Fee != nil {
+		record[7] = tx.Transaction.Fee.Amount.String()
+	}
+	record[8] = strconv.FormatBool(tx.Success)
+	return record
+}
+
+// GetAccountHistory returns staking events and transactions signed in the
+// history of an account, read from index when it holds range requested and
+// from node otherwise.
+func GetAccountHistory(w http.ResponseWriter, r *http.Request) {
+
+	// Retrieving address, range and page from query request
+	query, msg := checkHistoryQuery(r)
+	if len(msg) > 0 {
+
+		// Stop code here no need to establish connection and reply
+		w.Header().Add("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
+	var history []*responses.AccountHistoryItem
+	var next string
+	var err error
+	if indexCovers(query) {
+		history, next, err = indexedHistory(query)
+	} else {
+
+		// Attempt to load connection with consensus client
+		connection, co := loadConsensusClient(query.socket)
+
+		// Close connection once code underneath executes
+		defer connection.Close()
+
+		// If null object was retrieved send response
+		if co == nil {
+
+			// Stop code here faild to establish connection and reply
+			w.Header().Add("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(responses.ErrorResponse{
+				Error: "Failed to establish connection using socket: " +
+					query.socket})
+			return
+		}
+
+		// Range defaults to blocks retained by node
+		var status *consensus.Status
+		status, err = co.GetStatus(context.Background())
+		if err == nil {
+			if query.from == 0 {
+				query.from = status.LastRetainedHeight
+				if query.from < 1 {
+					query.from = 1
+				}
+			}
+			if query.to == 0 {
+				query.to = status.LatestHeight
+			}
+
+			var chainContext string
+			chainContext, err = getChainContext(query.socket)
+			if err == nil {
+				history, next, err = liveHistory(query, co, chainContext)
+			}
+		}
+	}
+	if err != nil {
+		w.Header().Add("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Account History!"})
+
+		lgr.Error.Println("Request at /api/staking/accounthistory failed "+
+			"to retrieve Account History : ", err)
+		return
+	}
+
+	lgr.Info.Println("Request at /api/staking/accounthistory responding " +
+		"with Account History!")
+
+	if !query.csv {
+		w.Header().Add("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(responses.AccountHistoryResponse{
+			History: history, Next: next})
+		return
+	}
+
+	// Cursor of next page is returned in a header alongside CSV
+	w.Header().Add("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename="+
+		"account_history_"+query.address.String()+".csv")
+	if len(next) > 0 {
+		w.Header().Set("X-Next-Cursor", next)
+	}
+
+	records := csv.NewWriter(w)
+	records.Write(historyColumns)
+	for _, item := range history {
+		records.Write(historyRecord(item))
+	}
+	records.Flush()
+}
@@ -0,0 +1,105 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	hdl "github.com/SimplyVC/oasis_api_server/src/handlers"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+)
+
+func Test_GetAccountHistory_BadNode(t *testing.T) {
+	rr := serveRequest(hdl.GetAccountHistory, "/api/staking/accounthistory",
+		map[string]string{"name": "Unicorn", "address": builderAddress})
+	checkErrorResponse(t, rr, `{"error":"Node name requested doesn't exist"}`)
+}
+
+func Test_GetAccountHistory_InvalidAddress(t *testing.T) {
+	rr := serveRequest(hdl.GetAccountHistory, "/api/staking/accounthistory",
+		map[string]string{"name": "Oasis_Local", "address": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Failed to UnmarshalText into `+
+		`Address."}`)
+}
+
+func Test_GetAccountHistory_InvalidLimit(t *testing.T) {
+	rr := serveRequest(hdl.GetAccountHistory, "/api/staking/accounthistory",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"limit": "1001"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, limit needs `+
+		`to be a number between 1 and 1000!"}`)
+}
+
+func Test_GetAccountHistory_InvalidCursor(t *testing.T) {
+	rr := serveRequest(hdl.GetAccountHistory, "/api/staking/accounthistory",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"cursor": "10-x-0"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, cursor needs `+
+		`to be the next cursor of a previous page!"}`)
+}
+
+func Test_GetAccountHistory_InvalidFormat(t *testing.T) {
+	rr := serveRequest(hdl.GetAccountHistory, "/api/staking/accounthistory",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"format": "xml"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, format needs `+
+		`to be either json or csv!"}`)
+}
+
+func Test_GetAccountHistory_InvalidRange(t *testing.T) {
+	rr := serveRequest(hdl.GetAccountHistory, "/api/staking/accounthistory",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"from_height": "10", "to_height": "5"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, start of `+
+		`range is after its end!"}`)
+}
+
+func Test_GetAccountHistory(t *testing.T) {
+	rr := serveRequest(hdl.GetAccountHistory, "/api/staking/accounthistory",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"from_height": "1", "to_height": "10"})
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	history := &responses.AccountHistoryResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), history)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	if history.History == nil {
+		t.Fatalf("handler returned unexpected body: got %v",
+			rr.Body.String())
+	}
+
+	// Items are within range of heights, oldest first
+	for i, item := range history.History {
+		if item.Height < 1 || item.Height > 10 {
+			t.Errorf("handler returned item out of range: got height %v",
+				item.Height)
+		}
+		if i > 0 && item.Height < history.History[i-1].Height {
+			t.Errorf("handler returned unordered items: got %v after %v",
+				item.Height, history.History[i-1].Height)
+		}
+	}
+}
+
+func Test_GetAccountHistory_CSV(t *testing.T) {
+	rr := serveRequest(hdl.GetAccountHistory, "/api/staking/accounthistory",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"from_height": "1", "to_height": "10", "format": "csv"})
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := "height,kind,type,tx_hash,from,to,amount,fee,success"
+	if !strings.HasPrefix(rr.Body.String(), expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/SimplyVC/oasis_api_server/src/indexer"
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+)
+
+// Number of blocks indexed between progress logs while catching up
+const indexerLogInterval = 1000
+
+var (
+	// Index of blocks, nil if indexer is disabled
+	blockIndex *indexer.Store
+
+	// Name of node followed by indexer
+	indexerNodeName string
+)
+
+// InitIndexer opens index stored at path and starts indexing blocks of node
+// with given name in the background. Indexing starts from startHeight, or
+// from the lowest height node still has, and resumes from the last height
+// indexed after a restart.
+func InitIndexer(nodeName string, path string, startHeight int64) error {
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation {
+		return fmt.Errorf("node %s isn't configured", nodeName)
+	}
+
+	store, err := indexer.Open(path)
+	if err != nil {
+		return err
+	}
+	blockIndex = store
+	indexerNodeName = nodeName
+
+	go keepSubscribed(context.Background(), "blocks of "+nodeName+
+		" for indexer", func(ctx context.Context, reset func()) error {
+		return followBlocks(ctx, socket, startHeight, reset)
+	})
+	return nil
+}
+
+// followBlocks indexes blocks of node at socket up to its latest height and
+// then every new block, until stream of blocks drops.
+func followBlocks(ctx context.Context, socket string, startHeight int64,
+	reset func()) error {
+
+	connection, co := loadConsensusClient(socket)
+	if co == nil {
+		return fmt.Errorf("failed to establish connection using socket: %s",
+			socket)
+	}
+	defer connection.Close()
+
+	chainContext, err := co.GetChainContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Subscribe before catching up so that no block is missed
+	blocks, sub, err := co.WatchBlocks(ctx)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	status, err := co.GetStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Blocks below lowest height retained by node can't be indexed
+	earliest := startHeight
+	if earliest < status.LastRetainedHeight {
+		lgr.Warning.Printf("Indexer can't start from height %d, node only "+
+			"retains blocks from height %d", earliest,
+			status.LastRetainedHeight)
+		earliest = status.LastRetainedHeight
+	}
+	if earliest < 1 {
+		earliest = 1
+	}
+
+	if err := indexUpTo(ctx, co, chainContext, earliest,
+		status.LastRetainedHeight, status.LatestHeight); err != nil {
+		return err
+	}
+	reset()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case blk, ok := <-blocks:
+			if !ok {
+				return errors.New("stream of blocks closed")
+			}
+			if err := indexUpTo(ctx, co, chainContext, earliest,
+				status.LastRetainedHeight, blk.Height); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// indexUpTo indexes every block following checkpoint up to height, starting
+// from earliest if nothing has been indexed yet. Blocks following
+// checkpoint which node no longer retains are skipped, leaving a gap in
+// index, as they can't be fetched anymore.
+func indexUpTo(ctx context.Context, co consensus.ClientBackend,
+	chainContext string, earliest int64, retained int64,
+	height int64) error {
+
+	status, err := blockIndex.Status()
+	if err != nil {
+		return err
+	}
+	next := status.Checkpoint + 1
+	if status.Checkpoint == 0 {
+		next = earliest
+	}
+	if status.Checkpoint > 0 && next < retained {
+		lgr.Warning.Printf("Indexer skipping heights %d to %d, node only "+
+			"retains blocks from height %d", next, retained-1, retained)
+		if err := blockIndex.Skip(retained - 1); err != nil {
+			return err
+		}
+		next = retained
+	}
+
+	for ; next <= height; next++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		block, accounts, err := fetchBlock(ctx, co, chainContext, next)
+		if err != nil {
+			return fmt.Errorf("failed to fetch block %d: %w", next, err)
+		}
+		if err := blockIndex.Put(next, block, accounts); err != nil {
+			return fmt.Errorf("failed to index block %d: %w", next, err)
+		}
+
+		if next%indexerLogInterval == 0 && next < height {
+			lgr.Info.Printf("Indexer reached height %d of %d", next, height)
+		}
+	}
+	return nil
+}
+
+// fetchBlock retrieves block at height with its transactions and events,
+// together with items of accounts taking part in them
+func fetchBlock(ctx context.Context, co consensus.ClientBackend,
+	chainContext string, height int64) (*responses.IndexedBlock,
+	[]indexer.AccountItem, error) {
+
+	blk, err := co.GetBlock(ctx, height)
+	if err != nil {
+		return nil, nil, err
+	}
+	txsWithResults, err := co.GetTransactionsWithResults(ctx, height)
+	if err != nil {
+		return nil, nil, err
+	}
+	stakingEvents, err := co.Staking().GetEvents(ctx, height)
+	if err != nil {
+		return nil, nil, err
+	}
+	registryEvents, err := co.Registry().GetEvents(ctx, height)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block := &responses.IndexedBlock{
+		Block:          blk,
+		Transactions:   transactionsWithResults(chainContext, txsWithResults),
+		StakingEvents:  stakingEvents,
+		RegistryEvents: registryEvents,
+	}
+
+	accounts := []indexer.AccountItem{}
+	for i, tx := range block.Transactions {
+		for _, address := range transactionAccounts(tx) {
+			accounts = append(accounts, indexer.AccountItem{
+				Address: address, Height: height,
+				Kind: indexer.ItemTransaction, Index: i})
+		}
+	}
+	for i, ev := range block.StakingEvents {
+		for _, address := range stakingEventAccounts(ev) {
+			accounts = append(accounts, indexer.AccountItem{
+				Address: address, Height: height,
+				Kind: indexer.ItemEvent, Index: i})
+		}
+	}
+	return block, accounts, nil
+}
+
+// GetIndexerStatus returns node followed by indexer and heights indexed
+func GetIndexerStatus(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	if blockIndex == nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Indexer is disabled!"})
+		lgr.Error.Println("Request at /api/indexer/status failed, indexer " +
+			"is disabled!")
+		return
+	}
+
+	status, err := blockIndex.Status()
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Indexer Status!"})
+
+		lgr.Error.Println("Request at /api/indexer/status failed to "+
+			"retrieve Indexer Status : ", err)
+		return
+	}
+
+	gaps := []*responses.IndexerGap{}
+	for _, gap := range status.Gaps {
+		gaps = append(gaps, &responses.IndexerGap{From: gap.From,
+			To: gap.To})
+	}
+
+	lgr.Info.Println("Request at /api/indexer/status responding with " +
+		"Indexer Status!")
+	json.NewEncoder(w).Encode(responses.IndexerStatusResponse{
+		IndexerStatus: &responses.IndexerStatus{
+			NodeName:    indexerNodeName,
+			FirstHeight: status.FirstHeight,
+			Checkpoint:  status.Checkpoint,
+			Gaps:        gaps,
+		}})
+}
+
+// GetIndexedBlock returns block at height together with its transactions,
+// their results and events, read from index
+func GetIndexedBlock(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	if blockIndex == nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Indexer is disabled!"})
+		lgr.Error.Println("Request at /api/indexer/block failed, indexer " +
+			"is disabled!")
+		return
+	}
+
+	// Retrieving height from query
+	recvHeight := r.URL.Query().Get("height")
+	height := checkHeight(recvHeight)
+	if height == -1 {
+
+		// Stop code here no need to read index and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, height needs to be " +
+				"a string representing an int!"})
+		return
+	}
+
+	// Latest height of index is its checkpoint
+	if height == consensus.HeightLatest {
+		status, err := blockIndex.Status()
+		if err != nil {
+			json.NewEncoder(w).Encode(responses.ErrorResponse{
+				Error: "Failed to retrieve Indexer Status!"})
+
+			lgr.Error.Println("Request at /api/indexer/block failed to "+
+				"retrieve Indexer Status : ", err)
+			return
+		}
+		height = status.Checkpoint
+	}
+
+	block, err := blockIndex.Get(height)
+	if err == indexer.ErrNotIndexed {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Block at height requested has not been indexed!"})
+		return
+	}
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Indexed Block!"})
+
+		lgr.Error.Println("Request at /api/indexer/block failed to "+
+			"retrieve Indexed Block : ", err)
+		return
+	}
+
+	lgr.Info.Println("Request at /api/indexer/block responding with " +
+		"Indexed Block!")
+	json.NewEncoder(w).Encode(responses.IndexedBlockResponse{
+		Height: height, IndexedBlock: block})
+}
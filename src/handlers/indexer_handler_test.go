@@ -0,0 +1,51 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	hdl "github.com/SimplyVC/oasis_api_server/src/handlers"
+)
+
+func Test_GetIndexerStatus_Disabled(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/indexer/status", nil)
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetIndexerStatus)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Indexer is disabled!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_GetIndexedBlock_Disabled(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/indexer/block", nil)
+	q := req.URL.Query()
+	q.Add("height", "1")
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetIndexedBlock)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Indexer is disabled!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
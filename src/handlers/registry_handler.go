@@ -88,7 +88,7 @@ func GetEntities(w http.ResponseWriter, r *http.Request) {
 	lgr.Info.Println("Request at /api/registry/entities responding with" +
 		" entities!")
 	json.NewEncoder(w).Encode(responses.EntitiesResponse{
-		Entities: entities})
+		Height: height, Entities: entities})
 }
 
 // GetNodes returns all registered nodes at specific block height
@@ -150,7 +150,8 @@ func GetNodes(w http.ResponseWriter, r *http.Request) {
 	// Respond with all nodes retrieved above
 	lgr.Info.Println(
 		"Request at /api/registry/nodes responding with Nodes!")
-	json.NewEncoder(w).Encode(responses.NodesResponse{Nodes: nodes})
+	json.NewEncoder(w).Encode(responses.NodesResponse{
+		Height: height, Nodes: nodes})
 }
 
 // GetRegistryEvents returns the events at specified block height.
@@ -212,7 +213,8 @@ func GetRegistryEvents(w http.ResponseWriter, r *http.Request) {
 	// Respond with events retrieved at height
 	lgr.Info.Println(
 		"Request at /api/registry/events responding with Events!")
-	json.NewEncoder(w).Encode(responses.RegistryEventsResponse{Events: events})
+	json.NewEncoder(w).Encode(responses.RegistryEventsResponse{
+		Height: height, Events: events})
 }
 
 // GetRuntimes returns all runtimes at specific block height
@@ -283,7 +285,7 @@ func GetRuntimes(w http.ResponseWriter, r *http.Request) {
 	lgr.Info.Println("Request at /api/registry/runtimes responding " +
 		"with runtimes!")
 	json.NewEncoder(w).Encode(responses.RuntimesResponse{
-		Runtimes: runtimes})
+		Height: height, Runtimes: runtimes})
 }
 
 // GetRegistryStateToGenesis returns StateToGenesis at the specified
@@ -316,6 +318,15 @@ func GetRegistryStateToGenesis(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Retrieving delivery options of response from query request
+	opts, msg := checkStreamOptions(r)
+	if len(msg) > 0 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
 	// Attempt to load connection with registry client
 	connection, ro := loadRegistryClient(socket)
 
@@ -347,8 +358,10 @@ func GetRegistryStateToGenesis(w http.ResponseWriter, r *http.Request) {
 	lgr.Info.Println(
 		"Request at /api/registry/genesis responding with Registry" +
 			" Genesis!")
-	json.NewEncoder(w).Encode(responses.RegistryGenesisResponse{
-		GenesisRegistry: genesisRegistry})
+	streamJSON(w, "/api/registry/genesis",
+		"registry_genesis_"+streamHeightName(height)+".json", opts,
+		responses.RegistryGenesisResponse{Height: height,
+			GenesisRegistry: genesisRegistry})
 }
 
 // GetEntity returns information with regards to single entity
@@ -437,7 +450,7 @@ func GetEntity(w http.ResponseWriter, r *http.Request) {
 	lgr.Info.Println("Request at /api/registry/entity responding with" +
 		" Registry Entity!")
 	json.NewEncoder(w).Encode(responses.RegistryEntityResponse{
-		Entity: registryEntity})
+		Height: height, Entity: registryEntity})
 }
 
 // GetNode returns information with regards to single entity
@@ -527,7 +540,7 @@ func GetNode(w http.ResponseWriter, r *http.Request) {
 	lgr.Info.Println("Request at /api/registry/node responding with " +
 		"Registry Node!")
 	json.NewEncoder(w).Encode(responses.RegistryNodeResponse{
-		Node: registryNode})
+		Height: height, Node: registryNode})
 }
 
 // GetNodeStatus returns eturns a node's status.
@@ -617,7 +630,7 @@ func GetNodeStatus(w http.ResponseWriter, r *http.Request) {
 	lgr.Info.Println("Request at /api/registry/nodestatus responding with " +
 		"Node Status!")
 	json.NewEncoder(w).Encode(responses.NodeStatusResponse{
-		NodeStatus: nodeStatus})
+		Height: height, NodeStatus: nodeStatus})
 }
 
 // GetRuntime returns information with regards to single entity
@@ -705,5 +718,5 @@ func GetRuntime(w http.ResponseWriter, r *http.Request) {
 	lgr.Info.Println("Request at /api/registry/runtime responding with " +
 		"Registry Runtime!")
 	json.NewEncoder(w).Encode(responses.RuntimeResponse{
-		Runtime: registryRuntime})
+		Height: height, Runtime: registryRuntime})
 }
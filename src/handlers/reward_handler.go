@@ -0,0 +1,778 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/SimplyVC/oasis_api_server/src/indexer"
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+// Duration of a year over which rewards are annualised
+const rewardYear = time.Duration(365.25 * 24 * float64(time.Hour))
+
+// Assumptions made by every reward estimate
+var rewardNotes = []string{
+	"Validator is elected and signs enough blocks to earn the epoch " +
+		"signing reward in every epoch",
+	"Escrow balance and delegation amount stay constant for annual " +
+		"amounts, while APY compounds rewards every epoch",
+	"Rewards for proposing blocks aren't included",
+	"Common pool holds enough tokens to pay every reward",
+}
+
+// rewardScale returns scale of step of reward schedule active at epoch, or
+// nil once schedule has ended
+func rewardScale(steps []staking.RewardStep,
+	epoch beacon.EpochTime) *quantity.Quantity {
+
+	for i := range steps {
+		if epoch < steps[i].Until {
+			return &steps[i].Scale
+		}
+	}
+	return nil
+}
+
+// epochReward returns reward earned in an epoch by escrow balance, and
+// commission taken from it, computed in the same way as the staking
+// application does.
+func epochReward(balance *quantity.Quantity, factor *quantity.Quantity,
+	scale *quantity.Quantity, rate *quantity.Quantity) (
+	gross *quantity.Quantity, commission *quantity.Quantity, err error) {
+
+	gross = balance.Clone()
+	commission = quantity.NewQuantity()
+	if scale == nil {
+		return quantity.NewQuantity(), commission, nil
+	}
+	if err = gross.Mul(factor); err != nil {
+		return nil, nil, err
+	}
+	if err = gross.Mul(scale); err != nil {
+		return nil, nil, err
+	}
+	if err = gross.Quo(staking.RewardAmountDenominator); err != nil {
+		return nil, nil, err
+	}
+
+	if rate != nil {
+		commission = gross.Clone()
+		if err = commission.Mul(rate); err != nil {
+			return nil, nil, err
+		}
+		if err = commission.Quo(
+			staking.CommissionRateDenominator); err != nil {
+			return nil, nil, err
+		}
+	}
+	return gross, commission, nil
+}
+
+// rewardRate returns fraction of escrow balance earned in an epoch, before
+// and after commission
+func rewardRate(factor *quantity.Quantity, scale *quantity.Quantity,
+	rate *quantity.Quantity) (float64, float64) {
+
+	if scale == nil {
+		return 0, 0
+	}
+	gross := new(big.Rat).SetFrac(factor.ToBigInt(),
+		staking.RewardAmountDenominator.ToBigInt())
+	gross.Mul(gross, new(big.Rat).SetInt(scale.ToBigInt()))
+
+	net := new(big.Rat).Set(gross)
+	if rate != nil {
+		kept := new(big.Rat).SetFrac(rate.ToBigInt(),
+			staking.CommissionRateDenominator.ToBigInt())
+		kept.Sub(big.NewRat(1, 1), kept)
+		net.Mul(net, kept)
+	}
+
+	grossRate, _ := gross.Float64()
+	netRate, _ := net.Float64()
+	return grossRate, netRate
+}
+
+// rewardAmounts returns reward split into commission and remainder, with
+// each formatted as tokens
+func rewardAmounts(units tokenUnits, gross *quantity.Quantity,
+	commission *quantity.Quantity) (*responses.RewardAmounts, error) {
+
+	net := gross.Clone()
+	if err := net.Sub(commission); err != nil {
+		return nil, err
+	}
+	return &responses.RewardAmounts{
+		Gross:            *gross,
+		GrossTokens:      units.format(gross),
+		Commission:       *commission,
+		CommissionTokens: units.format(commission),
+		Net:              *net,
+		NetTokens:        units.format(net),
+	}, nil
+}
+
+// estimateRewards estimates rewards of amount held in escrow of validator
+// for current epoch and summed over the epochs of the following year.
+func estimateRewards(units tokenUnits, params *staking.ConsensusParameters,
+	schedule *staking.CommissionSchedule, epoch beacon.EpochTime,
+	epochs int64, amount *quantity.Quantity) (*responses.RewardEstimate,
+	error) {
+
+	factor := &params.RewardFactorEpochSigned
+	annualGross := quantity.NewQuantity()
+	annualCommission := quantity.NewQuantity()
+
+	estimate := &responses.RewardEstimate{Amount: *amount,
+		AmountTokens: units.format(amount)}
+	for e := epoch; e < epoch+beacon.EpochTime(epochs); e++ {
+		gross, commission, err := epochReward(amount, factor,
+			rewardScale(params.RewardSchedule, e), schedule.CurrentRate(e))
+		if err != nil {
+			return nil, err
+		}
+
+		if e == epoch {
+			estimate.Epoch, err = rewardAmounts(units, gross, commission)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := annualGross.Add(gross); err != nil {
+			return nil, err
+		}
+		if err := annualCommission.Add(commission); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	estimate.Annual, err = rewardAmounts(units, annualGross,
+		annualCommission)
+	if err != nil {
+		return nil, err
+	}
+	return estimate, nil
+}
+
+// GetValidatorRewards estimates rewards earned by escrow account of a
+// validator, and optionally by an amount delegated to it, per epoch and
+// over a year together with its yield and the assumptions made.
+func GetValidatorRewards(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving name of node from query request
+	nodeName := r.URL.Query().Get("name")
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Node name requested doesn't exist"})
+		return
+	}
+
+	// Retrieving height from query request
+	recvHeight := r.URL.Query().Get("height")
+	height := checkHeight(recvHeight)
+	if height == -1 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, height needs to be a " +
+				"string representing an int!"})
+		return
+	}
+
+	// Retrieving address of validator from query request
+	var address staking.Address
+	err := address.UnmarshalText([]byte(r.URL.Query().Get("address")))
+	if err != nil {
+		lgr.Error.Println("Failed to UnmarshalText into Address", err)
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to UnmarshalText into Address."})
+		return
+	}
+
+	// Retrieving amount delegated from query request
+	var amount *quantity.Quantity
+	if recvAmount := r.URL.Query().Get("amount"); len(recvAmount) > 0 {
+		q, ok := checkQuantity(recvAmount)
+		if !ok {
+			json.NewEncoder(w).Encode(responses.ErrorResponse{
+				Error: "Unexpected value found, amount needs to be " +
+					"a string representing an int!"})
+			return
+		}
+		amount = &q
+	}
+
+	// Attempt to load connection with consensus client
+	connection, co := loadConsensusClient(socket)
+
+	// Close connection once code underneath executes
+	defer connection.Close()
+
+	// If null object was retrieved send response
+	if co == nil {
+
+		// Stop code here faild to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to establish connection using socket: " +
+				socket})
+		return
+	}
+
+	ctx := context.Background()
+	clock, err := loadEpochClock(ctx, co, height)
+	if err == nil && clock.epochDuration() <= 0 {
+		err = errEpochDuration
+	}
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Epoch Timing!"})
+
+		lgr.Error.Println("Request at /api/staking/validatorrewards "+
+			"failed to retrieve Epoch Timing : ", err)
+		return
+	}
+
+	rewards, err := validatorRewards(ctx, co.Staking(), clock, address,
+		amount)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to estimate Validator Rewards!"})
+
+		lgr.Error.Println("Request at /api/staking/validatorrewards "+
+			"failed to estimate Validator Rewards : ", err)
+		return
+	}
+
+	lgr.Info.Println("Request at /api/staking/validatorrewards " +
+		"responding with Validator Rewards!")
+	json.NewEncoder(w).Encode(responses.ValidatorRewardsResponse{
+		Height: height, ValidatorRewards: rewards})
+}
+
+// validatorRewards estimates rewards of escrow account of validator at
+// address and of amount delegated to it, if given.
+func validatorRewards(ctx context.Context, so staking.Backend,
+	clock *epochClock, address staking.Address,
+	amount *quantity.Quantity) (*responses.ValidatorRewards, error) {
+
+	units, err := getTokenUnits(ctx, so)
+	if err != nil {
+		return nil, err
+	}
+	params, err := so.ConsensusParameters(ctx, clock.height)
+	if err != nil {
+		return nil, err
+	}
+	commonPool, err := so.CommonPool(ctx, clock.height)
+	if err != nil {
+		return nil, err
+	}
+	totalSupply, err := so.TotalSupply(ctx, clock.height)
+	if err != nil {
+		return nil, err
+	}
+	account, err := accountAt(ctx, so, address, clock.height)
+	if err != nil {
+		return nil, err
+	}
+
+	epochs := int64(rewardYear / clock.epochDuration())
+	schedule := &account.Escrow.CommissionSchedule
+	rewards := &responses.ValidatorRewards{
+		TokenSymbol:  units.symbol,
+		Validator:    address,
+		CurrentEpoch: clock.epoch,
+		Assumptions: &responses.RewardAssumptions{
+			EpochDuration:           clock.epochDuration().Seconds(),
+			EpochsPerYear:           epochs,
+			RewardFactorEpochSigned: params.RewardFactorEpochSigned,
+			CommonPool:              *commonPool,
+			TotalSupply:             *totalSupply,
+			Notes:                   rewardNotes,
+		},
+	}
+	if rate := schedule.CurrentRate(clock.epoch); rate != nil {
+		rewards.CommissionRate = percentage(rate,
+			staking.CommissionRateDenominator)
+	}
+	if scale := rewardScale(params.RewardSchedule,
+		clock.epoch); scale != nil {
+		rewards.Assumptions.RewardScale = *scale
+	}
+	if steps := params.RewardSchedule; len(steps) > 0 {
+		rewards.Assumptions.RewardScheduleEnd = steps[len(steps)-1].Until
+	}
+
+	// Yield follows reward schedule and commission schedule over the year
+	compounded := 1.0
+	for e := clock.epoch; e < clock.epoch+beacon.EpochTime(epochs); e++ {
+		grossRate, netRate := rewardRate(&params.RewardFactorEpochSigned,
+			rewardScale(params.RewardSchedule, e), schedule.CurrentRate(e))
+		rewards.GrossAPR += grossRate * 100
+		rewards.APR += netRate * 100
+		compounded *= 1 + netRate
+	}
+	rewards.APY = (compounded - 1) * 100
+
+	rewards.Escrow, err = estimateRewards(units, params, schedule,
+		clock.epoch, epochs, &account.Escrow.Active.Balance)
+	if err != nil {
+		return nil, err
+	}
+	if amount != nil {
+		rewards.Delegation, err = estimateRewards(units, params, schedule,
+			clock.epoch, epochs, amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rewards, nil
+}
+
+// Largest number of epochs over which rewards are realised in a request
+const maxRewardEpochs = 1000
+
+// Columns of realised rewards exported as CSV
+var realisedRewardColumns = []string{"epoch", "height", "time", "validator",
+	"value", "reward", "reward_tokens"}
+
+// delegatedValueAt returns value in base units of active and debonding
+// delegations of delegator at height, per validator
+func delegatedValueAt(ctx context.Context, so staking.Backend,
+	delegator staking.Address, height int64) (map[staking.Address]*big.Int,
+	error) {
+
+	active, debonding, err := delegationsFor(ctx, so, delegator, height)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[staking.Address]*big.Int)
+	for _, delegation := range append(active, debonding...) {
+		amount, err := delegation.pool.StakeForShares(&delegation.shares)
+		if err != nil {
+			return nil, err
+		}
+		if values[delegation.validator] == nil {
+			values[delegation.validator] = new(big.Int)
+		}
+		values[delegation.validator].Add(values[delegation.validator],
+			amount.ToBigInt())
+	}
+	return values, nil
+}
+
+// realisedAmounts returns reward realised by delegations of delegator to
+// each validator over a span of blocks, given their values at its ends and
+// staking events of blocks after its start. Reward is change in value less
+// escrow added by delegator and plus debonding released to it.
+func realisedAmounts(delegator staking.Address,
+	before map[staking.Address]*big.Int, after map[staking.Address]*big.Int,
+	events []*staking.Event) map[staking.Address]*big.Int {
+
+	amounts := make(map[staking.Address]*big.Int)
+	amount := func(validator staking.Address) *big.Int {
+		if amounts[validator] == nil {
+			amounts[validator] = new(big.Int)
+		}
+		return amounts[validator]
+	}
+
+	for validator, value := range after {
+		amount(validator).Add(amount(validator), value)
+	}
+	for validator, value := range before {
+		amount(validator).Sub(amount(validator), value)
+	}
+
+	// Escrow moved by delegator itself isn't a reward
+	for _, ev := range events {
+		switch {
+		case ev.Escrow == nil:
+		case ev.Escrow.Add != nil && ev.Escrow.Add.Owner.Equal(delegator):
+			validator := ev.Escrow.Add.Escrow
+			amount(validator).Sub(amount(validator),
+				ev.Escrow.Add.Amount.ToBigInt())
+		case ev.Escrow.Reclaim != nil &&
+			ev.Escrow.Reclaim.Owner.Equal(delegator):
+			validator := ev.Escrow.Reclaim.Escrow
+			amount(validator).Add(amount(validator),
+				ev.Escrow.Reclaim.Amount.ToBigInt())
+		}
+	}
+	return amounts
+}
+
+// delegatorEvents returns staking events at heights in range which may
+// involve delegator, read from index if indexed is set and from node
+// otherwise
+func delegatorEvents(ctx context.Context, so staking.Backend,
+	delegator staking.Address, from int64, to int64,
+	indexed bool) ([]*staking.Event, error) {
+
+	events := []*staking.Event{}
+	if !indexed {
+		for height := from; height <= to; height++ {
+			heightEvents, err := so.GetEvents(ctx, height)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, heightEvents...)
+		}
+		return events, nil
+	}
+
+	var after *indexer.AccountItem
+	for {
+		items, err := blockIndex.AccountItems(delegator, from, to, after,
+			maxHistoryLimit)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			return events, nil
+		}
+
+		for i := range items {
+			after = &items[i]
+			if after.Kind != indexer.ItemEvent {
+				continue
+			}
+			ev, err := blockIndex.StakingEvent(after.Height, after.Index)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, ev)
+		}
+	}
+}
+
+// indexHolds checks whether index of named node holds every height in
+// range, without gaps, so that no event is missed when read from it
+func indexHolds(nodeName string, from int64, to int64) bool {
+	if !indexCovers(&historyQuery{nodeName: nodeName, from: from,
+		to: to}) {
+		return false
+	}
+
+	status, err := blockIndex.Status()
+	if err != nil {
+		return false
+	}
+	for _, gap := range status.Gaps {
+		if gap.From <= to && gap.To >= from {
+			return false
+		}
+	}
+	return true
+}
+
+// realiseEpoch returns rewards realised by delegations of delegator over
+// the epoch before given one, which are added to escrow in first block of
+// epoch together with proposer rewards and slashes of epoch before. Value
+// of delegations is compared between first blocks of both epochs, with
+// escrow moved by delegator in between read from index if indexed is set.
+func realiseEpoch(ctx context.Context, co consensus.ClientBackend,
+	units tokenUnits, delegator staking.Address, epoch beacon.EpochTime,
+	indexed bool) ([]*responses.RealisedReward, error) {
+
+	start, err := co.Beacon().GetEpochBlock(ctx, epoch-1)
+	if err != nil {
+		return nil, err
+	}
+	height, err := co.Beacon().GetEpochBlock(ctx, epoch)
+	if err != nil {
+		return nil, err
+	}
+	blk, err := co.GetBlock(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	before, err := delegatedValueAt(ctx, co.Staking(), delegator, start)
+	if err != nil {
+		return nil, err
+	}
+	after, err := delegatedValueAt(ctx, co.Staking(), delegator, height)
+	if err != nil {
+		return nil, err
+	}
+	events, err := delegatorEvents(ctx, co.Staking(), delegator, start+1,
+		height, indexed)
+	if err != nil {
+		return nil, err
+	}
+
+	amounts := realisedAmounts(delegator, before, after, events)
+	validators := []staking.Address{}
+	for validator := range amounts {
+		validators = append(validators, validator)
+	}
+	sort.Slice(validators, func(i, j int) bool {
+		return validators[i].String() < validators[j].String()
+	})
+
+	rewards := []*responses.RealisedReward{}
+	for _, validator := range validators {
+		value := quantity.NewQuantity()
+		if after[validator] != nil {
+			if err := value.FromBigInt(after[validator]); err != nil {
+				return nil, err
+			}
+		}
+
+		reward := amounts[validator]
+		rewards = append(rewards, &responses.RealisedReward{
+			Epoch:        epoch,
+			Height:       height,
+			Time:         blk.Time,
+			Validator:    validator,
+			Value:        *value,
+			Reward:       reward.String(),
+			RewardTokens: units.formatInt(reward),
+		})
+	}
+	return rewards, nil
+}
+
+// realiseRewards returns rewards realised by delegations of delegator at
+// the start of every epoch from first to last, summed per validator and in
+// total
+func realiseRewards(ctx context.Context, co consensus.ClientBackend,
+	units tokenUnits, delegator staking.Address, first beacon.EpochTime,
+	last beacon.EpochTime, indexed bool) (*responses.RealisedRewards,
+	error) {
+
+	// Range may hold no start of epoch
+	epochs := int64(last) - int64(first) + 1
+	if epochs < 0 {
+		epochs = 0
+	}
+
+	perEpoch := make([][]*responses.RealisedReward, epochs)
+	err := runWorkers(ctx, len(perEpoch), func(ctx context.Context,
+		i int) error {
+
+		rewards, err := realiseEpoch(ctx, co, units, delegator,
+			first+beacon.EpochTime(i), indexed)
+		perEpoch[i] = rewards
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	realised := &responses.RealisedRewards{
+		TokenSymbol: units.symbol,
+		Delegator:   delegator,
+		Epochs:      []*responses.RealisedReward{},
+		Totals:      []*responses.RealisedRewardTotal{},
+	}
+	totals := make(map[staking.Address]*big.Int)
+	total := new(big.Int)
+	for _, rewards := range perEpoch {
+		for _, reward := range rewards {
+			realised.Epochs = append(realised.Epochs, reward)
+
+			amount, _ := new(big.Int).SetString(reward.Reward, 10)
+			if totals[reward.Validator] == nil {
+				totals[reward.Validator] = new(big.Int)
+				realised.Totals = append(realised.Totals,
+					&responses.RealisedRewardTotal{
+						Validator: reward.Validator})
+			}
+			totals[reward.Validator].Add(totals[reward.Validator], amount)
+			total.Add(total, amount)
+		}
+	}
+
+	sort.Slice(realised.Totals, func(i, j int) bool {
+		return realised.Totals[i].Validator.String() <
+			realised.Totals[j].Validator.String()
+	})
+	for _, sum := range realised.Totals {
+		sum.Reward = totals[sum.Validator].String()
+		sum.RewardTokens = units.formatInt(totals[sum.Validator])
+	}
+	realised.Total = total.String()
+	realised.TotalTokens = units.formatInt(total)
+	return realised, nil
+}
+
+// GetRealisedRewards returns rewards realised by delegations of a delegator
+// over every epoch in a range, by tracking value of its shares from the
+// start of each epoch to the start of the next one, with totals per
+// validator.
+func GetRealisedRewards(w http.ResponseWriter, r *http.Request) {
+
+	// Retrieving name of node from query request
+	nodeName := r.URL.Query().Get("name")
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation {
+
+		// Stop code here no need to establish connection and reply
+		w.Header().Add("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Node name requested doesn't exist"})
+		return
+	}
+
+	realised, msg := realisedRewards(r, socket)
+	if len(msg) > 0 {
+		w.Header().Add("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
+	lgr.Info.Println("Request at /api/staking/realisedrewards responding " +
+		"with Realised Rewards!")
+
+	if r.URL.Query().Get("format") != "csv" {
+		w.Header().Add("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(responses.RealisedRewardsResponse{
+			RealisedRewards: realised})
+		return
+	}
+
+	w.Header().Add("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename="+
+		"realised_rewards_"+realised.Delegator.String()+".csv")
+
+	records := csv.NewWriter(w)
+	records.Write(realisedRewardColumns)
+	for _, reward := range realised.Epochs {
+		records.Write([]string{
+			strconv.FormatUint(uint64(reward.Epoch), 10),
+			strconv.FormatInt(reward.Height, 10),
+			reward.Time.UTC().Format(time.RFC3339),
+			reward.Validator.String(),
+			reward.Value.String(),
+			reward.Reward,
+			reward.RewardTokens,
+		})
+	}
+	records.Flush()
+}
+
+// realisedRewards parses request for rewards realised by a delegator and
+// computes them, returning an error message if request is invalid or
+// rewards can't be computed.
+func realisedRewards(r *http.Request, socket string) (
+	*responses.RealisedRewards, string) {
+
+	// Retrieving address of delegator from query request
+	var address staking.Address
+	err := address.UnmarshalText([]byte(r.URL.Query().Get("address")))
+	if err != nil {
+		lgr.Error.Println("Failed to UnmarshalText into Address", err)
+		return nil, "Failed to UnmarshalText into Address."
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "json", "csv":
+	default:
+		return nil, "Unexpected value found, format needs to be " +
+			"either json or csv!"
+	}
+
+	// Retrieving range from query request
+	from, to, msg := checkRange(r, socket)
+	if len(msg) > 0 {
+		return nil, msg
+	}
+
+	// Attempt to load connection with consensus client
+	connection, co := loadConsensusClient(socket)
+
+	// Close connection once code underneath executes
+	defer connection.Close()
+
+	// If null object was retrieved send response
+	if co == nil {
+		return nil, "Failed to establish connection using socket: " +
+			socket
+	}
+
+	failed := func(what string, err error) (*responses.RealisedRewards,
+		string) {
+
+		lgr.Error.Println("Request at /api/staking/realisedrewards failed "+
+			"to retrieve "+what+" : ", err)
+		return nil, "Failed to retrieve " + what + "!"
+	}
+
+	// Range defaults to blocks retained by node
+	ctx := context.Background()
+	status, err := co.GetStatus(ctx)
+	if err != nil {
+		return failed("Status", err)
+	}
+	if from == 0 {
+		from = status.LastRetainedHeight
+		if from < 1 {
+			from = 1
+		}
+	}
+	if to == 0 {
+		to = status.LatestHeight
+	}
+
+	// Rewards of an epoch are realised in first block of the next one, so
+	// epochs are covered if both of them start in range
+	first, err := co.Beacon().GetEpoch(ctx, from)
+	if err != nil {
+		return failed("Epoch", err)
+	}
+	start, err := co.Beacon().GetEpochBlock(ctx, first)
+	if err != nil {
+		return failed("Epoch Block", err)
+	}
+	if start < from {
+		first++
+		if start, err = co.Beacon().GetEpochBlock(ctx, first); err != nil {
+			return failed("Epoch Block", err)
+		}
+	}
+	last, err := co.Beacon().GetEpoch(ctx, to)
+	if err != nil {
+		return failed("Epoch", err)
+	}
+	first++
+	if last >= first && int64(last-first) >= maxRewardEpochs {
+		return nil, "Unexpected value found, range holds more than " +
+			strconv.Itoa(maxRewardEpochs) + " epochs!"
+	}
+
+	units, err := getTokenUnits(ctx, co.Staking())
+	if err != nil {
+		return failed("Token Units", err)
+	}
+
+	// Escrow moved by delegator is read from index when it holds range
+	indexed := indexHolds(r.URL.Query().Get("name"), start, to)
+	realised, err := realiseRewards(ctx, co, units, address, first, last,
+		indexed)
+	if err != nil {
+		return failed("Realised Rewards", err)
+	}
+	realised.FromHeight, realised.ToHeight = from, to
+	return realised, ""
+}
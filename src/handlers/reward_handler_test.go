@@ -0,0 +1,208 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"testing"
+
+	hdl "github.com/SimplyVC/oasis_api_server/src/handlers"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+func Test_GetValidatorRewards_BadNode(t *testing.T) {
+	rr := serveRequest(hdl.GetValidatorRewards,
+		"/api/staking/validatorrewards",
+		map[string]string{"name": "Unicorn", "address": builderAddress})
+	checkErrorResponse(t, rr, `{"error":"Node name requested doesn't exist"}`)
+}
+
+func Test_GetValidatorRewards_InvalidHeight(t *testing.T) {
+	rr := serveRequest(hdl.GetValidatorRewards,
+		"/api/staking/validatorrewards",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"height": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, height `+
+		`needs to be a string representing an int!"}`)
+}
+
+func Test_GetValidatorRewards_InvalidAddress(t *testing.T) {
+	rr := serveRequest(hdl.GetValidatorRewards,
+		"/api/staking/validatorrewards",
+		map[string]string{"name": "Oasis_Local", "address": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Failed to UnmarshalText into `+
+		`Address."}`)
+}
+
+func Test_GetValidatorRewards_InvalidAmount(t *testing.T) {
+	rr := serveRequest(hdl.GetValidatorRewards,
+		"/api/staking/validatorrewards",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"amount": "-1"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, amount `+
+		`needs to be a string representing an int!"}`)
+}
+
+func Test_GetValidatorRewards(t *testing.T) {
+	rr := serveRequest(hdl.GetValidatorRewards,
+		"/api/staking/validatorrewards",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"amount": "100000000000"})
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	rewards := &responses.ValidatorRewardsResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), rewards)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	result := rewards.ValidatorRewards
+	if result == nil || result.Delegation == nil ||
+		result.Delegation.Annual == nil {
+		t.Fatalf("handler returned unexpected body: got %v",
+			rr.Body.String())
+	}
+
+	// Reward is split into commission and net reward
+	annual := result.Delegation.Annual
+	sum := annual.Commission.Clone()
+	if err := sum.Add(&annual.Net); err != nil ||
+		sum.Cmp(&annual.Gross) != 0 {
+		t.Errorf("handler returned wrong split: got %v want %v",
+			sum, annual.Gross)
+	}
+	if result.APR > result.GrossAPR {
+		t.Errorf("handler returned APR above gross APR: got %v want <= %v",
+			result.APR, result.GrossAPR)
+	}
+}
+
+func Test_GetRealisedRewards_BadNode(t *testing.T) {
+	rr := serveRequest(hdl.GetRealisedRewards, "/api/staking/realisedrewards",
+		map[string]string{"name": "Unicorn", "address": builderAddress})
+	checkErrorResponse(t, rr, `{"error":"Node name requested doesn't exist"}`)
+}
+
+func Test_GetRealisedRewards_InvalidAddress(t *testing.T) {
+	rr := serveRequest(hdl.GetRealisedRewards, "/api/staking/realisedrewards",
+		map[string]string{"name": "Oasis_Local", "address": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Failed to UnmarshalText into `+
+		`Address."}`)
+}
+
+func Test_GetRealisedRewards_InvalidFormat(t *testing.T) {
+	rr := serveRequest(hdl.GetRealisedRewards, "/api/staking/realisedrewards",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"format": "xml"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, format needs `+
+		`to be either json or csv!"}`)
+}
+
+func Test_GetRealisedRewards_InvalidRange(t *testing.T) {
+	rr := serveRequest(hdl.GetRealisedRewards, "/api/staking/realisedrewards",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"from_height": "10", "to_height": "5"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, start of `+
+		`range is after its end!"}`)
+}
+
+func Test_GetRealisedRewards(t *testing.T) {
+	rr := serveRequest(hdl.GetRealisedRewards, "/api/staking/realisedrewards",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"from_height": "1", "to_height": "100"})
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	realised := &responses.RealisedRewardsResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), realised)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	result := realised.RealisedRewards
+	if result == nil || result.ToHeight != 100 {
+		t.Fatalf("handler returned unexpected body: got %v",
+			rr.Body.String())
+	}
+
+	// Total is sum of rewards of every epoch
+	total := new(big.Int)
+	for _, reward := range result.Epochs {
+		amount, ok := new(big.Int).SetString(reward.Reward, 10)
+		if !ok {
+			t.Fatalf("handler returned invalid reward: got %v",
+				reward.Reward)
+		}
+		total.Add(total, amount)
+	}
+	if total.String() != result.Total {
+		t.Errorf("handler returned wrong total: got %v want %v",
+			result.Total, total)
+	}
+}
+
+// testAddress returns address of public key made of given byte
+func testAddress(b byte) staking.Address {
+	var pk signature.PublicKey
+	pk[0] = b
+	return staking.NewAddress(pk)
+}
+
+func Test_RealisedAmounts(t *testing.T) {
+	delegator, other := testAddress(1), testAddress(2)
+	added, released, slashed, left := testAddress(3), testAddress(4),
+		testAddress(5), testAddress(6)
+
+	value := func(amount int64) *big.Int { return big.NewInt(amount) }
+	before := map[staking.Address]*big.Int{added: value(1000),
+		released: value(500), slashed: value(300), left: value(200)}
+	after := map[staking.Address]*big.Int{added: value(1150),
+		slashed: value(290)}
+
+	escrow := func(owner staking.Address, validator staking.Address,
+		amount uint64, add bool) *staking.Event {
+
+		if add {
+			return &staking.Event{Escrow: &staking.EscrowEvent{
+				Add: &staking.AddEscrowEvent{Owner: owner,
+					Escrow: validator,
+					Amount: *quantity.NewFromUint64(amount)}}}
+		}
+		return &staking.Event{Escrow: &staking.EscrowEvent{
+			Reclaim: &staking.ReclaimEscrowEvent{Owner: owner,
+				Escrow: validator,
+				Amount: *quantity.NewFromUint64(amount)}}}
+	}
+	events := []*staking.Event{
+		escrow(delegator, added, 100, true),
+		escrow(other, added, 5000, true),
+		escrow(delegator, released, 520, false),
+		escrow(delegator, left, 230, false),
+		{Escrow: &staking.EscrowEvent{Take: &staking.TakeEscrowEvent{
+			Owner: slashed, Amount: *quantity.NewFromUint64(30)}}},
+	}
+
+	// Escrow added or released isn't a reward, while a slash lowers it
+	expected := map[staking.Address]int64{added: 50, released: 20,
+		slashed: -10, left: 30}
+	amounts := hdl.RealisedAmounts(delegator, before, after, events)
+	if len(amounts) != len(expected) {
+		t.Errorf("function returned wrong number of validators: got %v "+
+			"want %v", len(amounts), len(expected))
+	}
+	for validator, want := range expected {
+		if amount := amounts[validator]; amount == nil ||
+			amount.Int64() != want {
+			t.Errorf("function returned wrong reward of %v: got %v "+
+				"want %v", validator, amount, want)
+		}
+	}
+}
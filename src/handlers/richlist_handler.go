@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+const (
+	// Number of accounts in a page of rich list if no limit is given
+	defaultRichListLimit = 100
+
+	// Largest number of accounts in a page of rich list
+	maxRichListLimit = 1000
+)
+
+// Numbers of richest accounts whose share of total supply is reported
+var richListTops = []int{10, 100, 1000}
+
+// ledgerEntry holds balances of an account at a height
+type ledgerEntry struct {
+	Address   staking.Address   `json:"address"`
+	Balance   quantity.Quantity `json:"balance"`
+	Escrow    quantity.Quantity `json:"escrow"`
+	Debonding quantity.Quantity `json:"debonding"`
+	Total     quantity.Quantity `json:"total"`
+}
+
+// ledgerOrders compare entries of ledger by each balance rich list can be
+// sorted by
+var ledgerOrders = map[string]func(a, b *ledgerEntry) int{
+	"total": func(a, b *ledgerEntry) int {
+		return a.Total.Cmp(&b.Total)
+	},
+	"balance": func(a, b *ledgerEntry) int {
+		return a.Balance.Cmp(&b.Balance)
+	},
+	"escrow": func(a, b *ledgerEntry) int {
+		return a.Escrow.Cmp(&b.Escrow)
+	},
+	"debonding": func(a, b *ledgerEntry) int {
+		return a.Debonding.Cmp(&b.Debonding)
+	},
+}
+
+// richListQuery holds inputs of a request for a rich list
+type richListQuery struct {
+	nodeName string
+	socket   string
+	height   int64
+	sort     string
+	order    string
+	offset   int
+	limit    int
+}
+
+// checkRichListQuery parses inputs of a request for a rich list, returning
+// an error message if any of them is invalid.
+func checkRichListQuery(r *http.Request) (*richListQuery, string) {
+	q := r.URL.Query()
+	query := &richListQuery{nodeName: q.Get("name"), sort: "total",
+		order: "desc", limit: defaultRichListLimit}
+
+	confirmation, socket := checkNodeName(query.nodeName)
+	if !confirmation {
+		return nil, "Node name requested doesn't exist"
+	}
+	query.socket = socket
+
+	if query.height = checkHeight(q.Get("height")); query.height == -1 {
+		return nil, "Unexpected value found, height needs to be a " +
+			"string representing an int!"
+	}
+
+	if recvSort := q.Get("sort"); len(recvSort) > 0 {
+		if _, ok := ledgerOrders[recvSort]; !ok {
+			return nil, "Unexpected value found, sort needs to be one of " +
+				"total, balance, escrow and debonding!"
+		}
+		query.sort = recvSort
+	}
+
+	switch recvOrder := q.Get("order"); recvOrder {
+	case "":
+	case "desc", "asc":
+		query.order = recvOrder
+	default:
+		return nil, "Unexpected value found, order needs to be either " +
+			"desc or asc!"
+	}
+
+	if recvLimit := q.Get("limit"); len(recvLimit) > 0 {
+		limit, err := strconv.Atoi(recvLimit)
+		if err != nil || limit < 1 || limit > maxRichListLimit {
+			return nil, "Unexpected value found, limit needs to be " +
+				"a number between 1 and " +
+				strconv.Itoa(maxRichListLimit) + "!"
+		}
+		query.limit = limit
+	}
+
+	if recvOffset := q.Get("offset"); len(recvOffset) > 0 {
+		offset, err := strconv.Atoi(recvOffset)
+		if err != nil || offset < 0 {
+			return nil, "Unexpected value found, offset needs to be " +
+				"a non-negative number!"
+		}
+		query.offset = offset
+	}
+	return query, ""
+}
+
+// loadLedger looks up balances of every account at height using a bounded
+// pool of workers. Ledgers are kept in response cache, if it is enabled,
+// as they never change at a given height.
+func loadLedger(ctx context.Context, so staking.Backend, nodeName string,
+	height int64) ([]*ledgerEntry, error) {
+
+	key := "ledger:" + nodeName + ":" + strconv.FormatInt(height, 10)
+	if responseCache != nil {
+		if cached, ok := responseCache.Get(key); ok {
+			ledger := []*ledgerEntry{}
+			if err := json.Unmarshal(cached, &ledger); err == nil {
+				return ledger, nil
+			}
+		}
+	}
+
+	addresses, err := so.Addresses(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	ledger := make([]*ledgerEntry, len(addresses))
+	err = runWorkers(ctx, len(addresses), func(ctx context.Context,
+		i int) error {
+
+		account, err := accountAt(ctx, so, addresses[i], height)
+		if err != nil {
+			return err
+		}
+		entry := &ledgerEntry{
+			Address:   addresses[i],
+			Balance:   account.General.Balance,
+			Escrow:    account.Escrow.Active.Balance,
+			Debonding: account.Escrow.Debonding.Balance,
+		}
+		entry.Total = *entry.Balance.Clone()
+		if err := entry.Total.Add(&entry.Escrow); err != nil {
+			return err
+		}
+		if err := entry.Total.Add(&entry.Debonding); err != nil {
+			return err
+		}
+		ledger[i] = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if responseCache != nil {
+		if encoded, err := json.Marshal(ledger); err == nil {
+			responseCache.Set(key, encoded, 0)
+		}
+	}
+	return ledger, nil
+}
+
+// sortLedger sorts entries of ledger by balance, breaking ties by address
+// so that pages are stable
+func sortLedger(ledger []*ledgerEntry, by string, descending bool) {
+	compare := ledgerOrders[by]
+	sort.Slice(ledger, func(i, j int) bool {
+		if c := compare(ledger[i], ledger[j]); c != 0 {
+			return (c > 0) == descending
+		}
+		return bytes.Compare(ledger[i].Address[:],
+			ledger[j].Address[:]) < 0
+	})
+}
+
+// giniCoefficient returns Gini coefficient of totals of accounts holding
+// tokens, given ledger sorted by ascending total
+func giniCoefficient(ledger []*ledgerEntry) float64 {
+	// With totals x_1 <= ... <= x_n the coefficient is
+	// 2 * sum(i * x_i) / (n * sum(x_i)) - (n + 1) / n
+	weighted, sum := new(big.Int), new(big.Int)
+	n := int64(0)
+	for _, entry := range ledger {
+		if entry.Total.IsZero() {
+			continue
+		}
+		n++
+		total := entry.Total.ToBigInt()
+		sum.Add(sum, total)
+		weighted.Add(weighted, new(big.Int).Mul(total, big.NewInt(n)))
+	}
+	if sum.Sign() == 0 {
+		return 0
+	}
+
+	gini := new(big.Rat).SetFrac(new(big.Int).Lsh(weighted, 1),
+		new(big.Int).Mul(sum, big.NewInt(n)))
+	gini.Sub(gini, big.NewRat(n+1, n))
+	value, _ := gini.Float64()
+	return value
+}
+
+// stakeDistribution computes statistics of distribution of tokens over
+// ledger, sorting it by descending total
+func stakeDistribution(ledger []*ledgerEntry, totalSupply *quantity.Quantity,
+	commonPool *quantity.Quantity) (*responses.StakeDistribution, error) {
+
+	distribution := &responses.StakeDistribution{
+		Accounts:        len(ledger),
+		TotalSupply:     *totalSupply,
+		CommonPool:      *commonPool,
+		CommonPoolShare: percentage(commonPool, totalSupply),
+		TopShares:       []*responses.TopShare{},
+	}
+
+	sortLedger(ledger, "total", false)
+	distribution.Gini = giniCoefficient(ledger)
+
+	sortLedger(ledger, "total", true)
+	for _, top := range richListTops {
+		held := quantity.NewQuantity()
+		for i := 0; i < top && i < len(ledger); i++ {
+			if err := held.Add(&ledger[i].Total); err != nil {
+				return nil, err
+			}
+		}
+		distribution.TopShares = append(distribution.TopShares,
+			&responses.TopShare{Accounts: top,
+				Share: percentage(held, totalSupply)})
+	}
+	return distribution, nil
+}
+
+// richList ranks accounts at height of query and returns requested page of
+// them together with statistics of distribution of tokens
+func richList(ctx context.Context, co consensus.ClientBackend,
+	query *richListQuery) (*responses.RichList, error) {
+
+	// Latest height is resolved so that ledger is cached at the height
+	// it was read at
+	blk, err := co.GetBlock(ctx, query.height)
+	if err != nil {
+		return nil, err
+	}
+	height := blk.Height
+
+	so := co.Staking()
+	units, err := getTokenUnits(ctx, so)
+	if err != nil {
+		return nil, err
+	}
+	totalSupply, err := so.TotalSupply(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	commonPool, err := so.CommonPool(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	ledger, err := loadLedger(ctx, so, query.nodeName, height)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &responses.RichList{
+		TokenSymbol: units.symbol,
+		Sort:        query.sort,
+		Order:       query.order,
+		Offset:      query.offset,
+		Limit:       query.limit,
+		Accounts:    []*responses.RichListAccount{},
+	}
+	list.Distribution, err = stakeDistribution(ledger, totalSupply,
+		commonPool)
+	if err != nil {
+		return nil, err
+	}
+
+	sortLedger(ledger, query.sort, query.order == "desc")
+	for i := query.offset; i < len(ledger) &&
+		i < query.offset+query.limit; i++ {
+		entry := ledger[i]
+		list.Accounts = append(list.Accounts, &responses.RichListAccount{
+			Rank:        i + 1,
+			Address:     entry.Address,
+			Balance:     entry.Balance,
+			Escrow:      entry.Escrow,
+			Debonding:   entry.Debonding,
+			Total:       entry.Total,
+			TotalTokens: units.format(&entry.Total),
+			Share:       percentage(&entry.Total, totalSupply),
+		})
+	}
+	return list, nil
+}
+
+// GetRichList returns a page of all accounts ranked by total, general,
+// active escrow or debonding escrow balance together with statistics of
+// distribution of tokens such as Gini coefficient and share of total
+// supply held by richest accounts and by common pool.
+func GetRichList(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving node, height, ordering and page from query request
+	query, msg := checkRichListQuery(r)
+	if len(msg) > 0 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
+	// Attempt to load connection with consensus client
+	connection, co := loadConsensusClient(query.socket)
+
+	// Close connection once code underneath executes
+	defer connection.Close()
+
+	// If null object was retrieved send response
+	if co == nil {
+
+		// Stop code here faild to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to establish connection using socket: " +
+				query.socket})
+		return
+	}
+
+	list, err := richList(context.Background(), co, query)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Rich List!"})
+
+		lgr.Error.Println("Request at /api/staking/richlist failed to "+
+			"retrieve Rich List : ", err)
+		return
+	}
+
+	lgr.Info.Println("Request at /api/staking/richlist responding with " +
+		"Rich List!")
+	json.NewEncoder(w).Encode(responses.RichListResponse{
+		Height: query.height, RichList: list})
+}
@@ -0,0 +1,94 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	hdl "github.com/SimplyVC/oasis_api_server/src/handlers"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+)
+
+func Test_GetRichList_BadNode(t *testing.T) {
+	rr := serveRequest(hdl.GetRichList, "/api/staking/richlist",
+		map[string]string{"name": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Node name requested doesn't exist"}`)
+}
+
+func Test_GetRichList_InvalidHeight(t *testing.T) {
+	rr := serveRequest(hdl.GetRichList, "/api/staking/richlist",
+		map[string]string{"name": "Oasis_Local", "height": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, height `+
+		`needs to be a string representing an int!"}`)
+}
+
+func Test_GetRichList_InvalidSort(t *testing.T) {
+	rr := serveRequest(hdl.GetRichList, "/api/staking/richlist",
+		map[string]string{"name": "Oasis_Local", "sort": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, sort needs `+
+		`to be one of total, balance, escrow and debonding!"}`)
+}
+
+func Test_GetRichList_InvalidOrder(t *testing.T) {
+	rr := serveRequest(hdl.GetRichList, "/api/staking/richlist",
+		map[string]string{"name": "Oasis_Local", "order": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, order `+
+		`needs to be either desc or asc!"}`)
+}
+
+func Test_GetRichList_InvalidLimit(t *testing.T) {
+	rr := serveRequest(hdl.GetRichList, "/api/staking/richlist",
+		map[string]string{"name": "Oasis_Local", "limit": "0"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, limit `+
+		`needs to be a number between 1 and 1000!"}`)
+}
+
+func Test_GetRichList_InvalidOffset(t *testing.T) {
+	rr := serveRequest(hdl.GetRichList, "/api/staking/richlist",
+		map[string]string{"name": "Oasis_Local", "offset": "-1"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, offset `+
+		`needs to be a non-negative number!"}`)
+}
+
+func Test_GetRichList(t *testing.T) {
+	rr := serveRequest(hdl.GetRichList, "/api/staking/richlist",
+		map[string]string{"name": "Oasis_Local", "sort": "escrow",
+			"limit": "10"})
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	list := &responses.RichListResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), list)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	result := list.RichList
+	if result == nil || result.Distribution == nil ||
+		len(result.Accounts) > 10 {
+		t.Fatalf("handler returned unexpected body: got %v",
+			rr.Body.String())
+	}
+
+	for i, account := range result.Accounts {
+		if account.Rank != i+1 {
+			t.Errorf("handler returned wrong rank: got %v want %v",
+				account.Rank, i+1)
+		}
+		if i > 0 && account.Escrow.Cmp(&result.Accounts[i-1].Escrow) > 0 {
+			t.Errorf("handler returned unordered accounts: got %v after %v",
+				account.Escrow, result.Accounts[i-1].Escrow)
+		}
+
+		// Total is sum of general, escrow and debonding balances
+		total := account.Balance.Clone()
+		total.Add(&account.Escrow)
+		total.Add(&account.Debonding)
+		if total.Cmp(&account.Total) != 0 {
+			t.Errorf("handler returned wrong total: got %v want %v",
+				account.Total, total)
+		}
+	}
+}
@@ -87,7 +87,7 @@ func GetValidators(w http.ResponseWriter, r *http.Request) {
 	lgr.Info.Println("Request at /api/scheduler/validators responding " +
 		"with Validators!")
 	json.NewEncoder(w).Encode(responses.ValidatorsResponse{
-		Validators: validators})
+		Height: height, Validators: validators})
 }
 
 // GetCommittees returns vector of committees for given
@@ -177,7 +177,7 @@ func GetCommittees(w http.ResponseWriter, r *http.Request) {
 	lgr.Info.Println("Request at /api/scheduler/committees responding " +
 		"with Committees!")
 	json.NewEncoder(w).Encode(responses.CommitteesResponse{
-		Committee: committees})
+		Height: height, Committee: committees})
 }
 
 // GetSchedulerStateToGenesis returns genesis state of scheduler at the
@@ -240,5 +240,5 @@ func GetSchedulerStateToGenesis(w http.ResponseWriter, r *http.Request) {
 	lgr.Info.Println("Request at /api/scheduler/genesis responding with " +
 		"scheduler genesis state!")
 	json.NewEncoder(w).Encode(responses.SchedulerGenesisState{
-		SchedulerGenesisState: gensis})
+		Height: height, SchedulerGenesisState: gensis})
 }
@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+// Largest number of samples in a series
+const maxSeriesSamples = 1000
+
+// seriesSample resolves height at which a sample of a series is taken
+type seriesSample func(ctx context.Context) (int64, error)
+
+// checkSeriesInterval parses interval between samples, given as exactly
+// one of interval_blocks, interval_epochs or interval, returning an error
+// message if it is invalid.
+func checkSeriesInterval(r *http.Request) (int64, int64, time.Duration,
+	string) {
+
+	q := r.URL.Query()
+	given := 0
+	for _, param := range []string{"interval_blocks", "interval_epochs",
+		"interval"} {
+		if len(q.Get(param)) > 0 {
+			given++
+		}
+	}
+	if given != 1 {
+		return 0, 0, 0, "Unexpected value found, exactly one of " +
+			"interval_blocks, interval_epochs and interval needs to be " +
+			"given!"
+	}
+
+	if recvBlocks := q.Get("interval_blocks"); len(recvBlocks) > 0 {
+		blocks, err := strconv.ParseInt(recvBlocks, 10, 64)
+		if err != nil || blocks <= 0 {
+			return 0, 0, 0, "Unexpected value found, interval_blocks " +
+				"needs to be a string representing a positive int!"
+		}
+		return blocks, 0, 0, ""
+	}
+	if recvEpochs := q.Get("interval_epochs"); len(recvEpochs) > 0 {
+		epochs, err := strconv.ParseInt(recvEpochs, 10, 64)
+		if err != nil || epochs <= 0 {
+			return 0, 0, 0, "Unexpected value found, interval_epochs " +
+				"needs to be a string representing a positive int!"
+		}
+		return 0, epochs, 0, ""
+	}
+
+	interval, err := time.ParseDuration(q.Get("interval"))
+	if err != nil || interval <= 0 {
+		return 0, 0, 0, "Unexpected value found, interval needs to be " +
+			"a positive duration such as 24h!"
+	}
+	return 0, 0, interval, ""
+}
+
+// seriesSamples returns samples spaced by interval over range of heights,
+// or an error message if there are too many of them.
+func seriesSamples(ctx context.Context, co consensus.ClientBackend,
+	from int64, to int64, blocks int64, epochs int64,
+	interval time.Duration) ([]seriesSample, string, error) {
+
+	tooMany := "Unexpected value found, range holds more than " +
+		strconv.Itoa(maxSeriesSamples) + " samples!"
+	samples := []seriesSample{}
+
+	switch {
+	case blocks > 0:
+		if (to-from)/blocks >= maxSeriesSamples {
+			return nil, tooMany, nil
+		}
+		for height := from; height <= to; height += blocks {
+			height := height
+			samples = append(samples, func(context.Context) (int64,
+				error) {
+				return height, nil
+			})
+		}
+
+	case epochs > 0:
+		first, err := co.Beacon().GetEpoch(ctx, from)
+		if err != nil {
+			return nil, "", err
+		}
+		last, err := co.Beacon().GetEpoch(ctx, to)
+		if err != nil {
+			return nil, "", err
+		}
+		if int64(last-first)/epochs >= maxSeriesSamples {
+			return nil, tooMany, nil
+		}
+
+		// Epochs are sampled at their first block, except for epoch in
+		// progress at start of range which is sampled at its start
+		for epoch := first; epoch <= last; epoch += beacon.EpochTime(
+			epochs) {
+			epoch := epoch
+			samples = append(samples, func(ctx context.Context) (int64,
+				error) {
+				height, err := co.Beacon().GetEpochBlock(ctx, epoch)
+				if height < from {
+					height = from
+				}
+				return height, err
+			})
+		}
+
+	default:
+		fromBlock, err := co.GetBlock(ctx, from)
+		if err != nil {
+			return nil, "", err
+		}
+		toBlock, err := co.GetBlock(ctx, to)
+		if err != nil {
+			return nil, "", err
+		}
+		if toBlock.Time.Sub(fromBlock.Time)/interval >= maxSeriesSamples {
+			return nil, tooMany, nil
+		}
+
+		// Times are sampled at last block produced at or before them
+		times := []time.Time{}
+		for at := fromBlock.Time; !at.After(toBlock.Time); at = at.Add(
+			interval) {
+			times = append(times, at)
+		}
+		heights := make([]int64, len(times))
+		if err := resolveTimes(ctx, co, times, from, to,
+			heights); err != nil {
+			return nil, "", err
+		}
+		for _, height := range heights {
+			height := height
+			samples = append(samples, func(context.Context) (int64,
+				error) {
+				return height, nil
+			})
+		}
+	}
+	return samples, "", nil
+}
+
+// resolveTimes stores in heights the height of last block produced at or
+// before each of times, given in ascending order, between heights low and
+// high. Block at low must have been produced at or before first time. Each
+// time is searched for only between heights resolved for its neighbours.
+func resolveTimes(ctx context.Context, co consensus.ClientBackend,
+	times []time.Time, low int64, high int64, heights []int64) error {
+
+	if len(times) == 0 {
+		return nil
+	}
+	mid := len(times) / 2
+	height, err := searchTime(ctx, co, times[mid], low, high)
+	if err != nil {
+		return err
+	}
+	heights[mid] = height
+
+	if err := resolveTimes(ctx, co, times[:mid], low, height,
+		heights[:mid]); err != nil {
+		return err
+	}
+	return resolveTimes(ctx, co, times[mid+1:], height, high,
+		heights[mid+1:])
+}
+
+// sampleSeries looks up balances of account at each sample using a bounded
+// pool of workers, stopping at the first error.
+func sampleSeries(ctx context.Context, co consensus.ClientBackend,
+	address staking.Address, samples []seriesSample) (
+	[]*responses.AccountSeriesPoint, error) {
+
+	series := make([]*responses.AccountSeriesPoint, len(samples))
+	err := runWorkers(ctx, len(samples), func(ctx context.Context,
+		i int) error {
+
+		point, err := samplePoint(ctx, co, address, samples[i])
+		series[i] = point
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// samplePoint looks up balances of account at height of sample
+func samplePoint(ctx context.Context, co consensus.ClientBackend,
+	address staking.Address, sample seriesSample) (
+	*responses.AccountSeriesPoint, error) {
+
+	height, err := sample(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blk, err := co.GetBlock(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	account, err := accountAt(ctx, co.Staking(), address, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return &responses.AccountSeriesPoint{
+		Height:    height,
+		Time:      blk.Time,
+		Balance:   account.General.Balance,
+		Escrow:    account.Escrow.Active.Balance,
+		Debonding: account.Escrow.Debonding.Balance,
+	}, nil
+}
+
+// GetAccountSeries returns general balance, active escrow and debonding
+// escrow of an account sampled at heights spaced by a number of blocks, a
+// number of epochs or a duration over a range.
+func GetAccountSeries(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving name of node from query request
+	nodeName := r.URL.Query().Get("name")
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Node name requested doesn't exist"})
+		return
+	}
+
+	// Retrieving address from query request
+	var address staking.Address
+	err := address.UnmarshalText([]byte(r.URL.Query().Get("address")))
+	if err != nil {
+		lgr.Error.Println("Failed to UnmarshalText into Address", err)
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to UnmarshalText into Address."})
+		return
+	}
+
+	// Retrieving interval between samples from query request
+	blocks, epochs, interval, msg := checkSeriesInterval(r)
+	if len(msg) > 0 {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
+	// Retrieving range from query request
+	from, to, msg := checkRange(r, socket)
+	if len(msg) > 0 {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
+	// Attempt to load connection with consensus client
+	connection, co := loadConsensusClient(socket)
+
+	// Close connection once code underneath executes
+	defer connection.Close()
+
+	// If null object was retrieved send response
+	if co == nil {
+
+		// Stop code here faild to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to establish connection using socket: " +
+				socket})
+		return
+	}
+
+	// Range defaults to blocks retained by node
+	ctx := context.Background()
+	status, err := co.GetStatus(ctx)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Status!"})
+
+		lgr.Error.Println("Request at /api/staking/accountseries failed "+
+			"to retrieve Status : ", err)
+		return
+	}
+	if from == 0 {
+		from = status.LastRetainedHeight
+		if from < 1 {
+			from = 1
+		}
+	}
+	if to == 0 {
+		to = status.LatestHeight
+	}
+
+	samples, msg, err := seriesSamples(ctx, co, from, to, blocks, epochs,
+		interval)
+	if len(msg) > 0 {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
+	var series []*responses.AccountSeriesPoint
+	if err == nil {
+		series, err = sampleSeries(ctx, co, address, samples)
+	}
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Account Series!"})
+
+		lgr.Error.Println("Request at /api/staking/accountseries failed "+
+			"to retrieve Account Series : ", err)
+		return
+	}
+
+	lgr.Info.Println("Request at /api/staking/accountseries responding " +
+		"with Account Series!")
+	json.NewEncoder(w).Encode(responses.AccountSeriesResponse{
+		Series: series})
+}
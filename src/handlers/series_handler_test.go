@@ -0,0 +1,89 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	hdl "github.com/SimplyVC/oasis_api_server/src/handlers"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+)
+
+func Test_GetAccountSeries_BadNode(t *testing.T) {
+	rr := serveRequest(hdl.GetAccountSeries, "/api/staking/accountseries",
+		map[string]string{"name": "Unicorn", "address": builderAddress})
+	checkErrorResponse(t, rr, `{"error":"Node name requested doesn't exist"}`)
+}
+
+func Test_GetAccountSeries_InvalidAddress(t *testing.T) {
+	rr := serveRequest(hdl.GetAccountSeries, "/api/staking/accountseries",
+		map[string]string{"name": "Oasis_Local", "address": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Failed to UnmarshalText into `+
+		`Address."}`)
+}
+
+func Test_GetAccountSeries_MissingInterval(t *testing.T) {
+	rr := serveRequest(hdl.GetAccountSeries, "/api/staking/accountseries",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, exactly one `+
+		`of interval_blocks, interval_epochs and interval needs to be `+
+		`given!"}`)
+}
+
+func Test_GetAccountSeries_InvalidInterval(t *testing.T) {
+	rr := serveRequest(hdl.GetAccountSeries, "/api/staking/accountseries",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"interval": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, interval `+
+		`needs to be a positive duration such as 24h!"}`)
+}
+
+func Test_GetAccountSeries_InvalidIntervalBlocks(t *testing.T) {
+	rr := serveRequest(hdl.GetAccountSeries, "/api/staking/accountseries",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"interval_blocks": "0"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, `+
+		`interval_blocks needs to be a string representing a positive `+
+		`int!"}`)
+}
+
+func Test_GetAccountSeries_InvalidRange(t *testing.T) {
+	rr := serveRequest(hdl.GetAccountSeries, "/api/staking/accountseries",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"interval_blocks": "1", "from_height": "10", "to_height": "5"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, start of `+
+		`range is after its end!"}`)
+}
+
+func Test_GetAccountSeries(t *testing.T) {
+	rr := serveRequest(hdl.GetAccountSeries, "/api/staking/accountseries",
+		map[string]string{"name": "Oasis_Local", "address": builderAddress,
+			"interval_blocks": "5", "from_height": "1", "to_height": "20"})
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	series := &responses.AccountSeriesResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), series)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	if len(series.Series) != 4 {
+		t.Fatalf("handler returned unexpected body: got %v",
+			rr.Body.String())
+	}
+
+	// Samples are taken every 5 blocks from first height
+	for i, point := range series.Series {
+		if height := int64(1 + 5*i); point.Height != height {
+			t.Errorf("handler returned wrong height: got %v want %v",
+				point.Height, height)
+		}
+		if i > 0 && point.Time.Before(series.Series[i-1].Time) {
+			t.Errorf("handler returned unordered times: got %v after %v",
+				point.Time, series.Series[i-1].Time)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/consensus/tendermint/crypto"
+	tmcrypto "github.com/tendermint/tendermint/crypto"
+)
+
+// setMemberAt returns member of set with Tendermint address, or nil if it
+// isn't a member
+func setMemberAt(set *validatorSet, address tmcrypto.Address) *setMember {
+	for _, member := range set.members {
+		if bytes.Equal(member.address, address) {
+			return member
+		}
+	}
+	return nil
+}
+
+// nodeSigning tallies blocks signed, missed and proposed by node with
+// consensus key over window. Proposals are expected in proportion to
+// voting power of node, as Tendermint rotates proposers by voting power.
+func nodeSigning(window *commitWindow, nodeID signature.PublicKey,
+	consensusKey signature.PublicKey) *responses.NodeSigning {
+
+	address := crypto.PublicKeyToTendermint(&consensusKey).Address()
+	signing := &responses.NodeSigning{
+		NodeID:             nodeID,
+		ConsensusPublicKey: consensusKey,
+		TendermintAddress:  address,
+		Proposals:          []*responses.NodeBlock{},
+		Misses:             []*responses.NodeBlock{},
+	}
+
+	uptimes := validatorUptimes(window)
+	signing.FromHeight = uptimes.FromHeight
+	signing.ToHeight = uptimes.ToHeight
+	signing.Blocks = uptimes.Blocks
+	signing.Unattributed = uptimes.Unattributed
+	for _, uptime := range uptimes.Validators {
+		if bytes.Equal(uptime.TendermintAddress, address) {
+			signing.Expected = uptime.Expected
+			signing.Signed = uptime.Signed
+			signing.Missed = uptime.Missed
+			signing.Uptime = uptime.Uptime
+			signing.LongestMissedStreak = uptime.LongestMissedStreak
+			signing.CurrentMissedStreak = uptime.CurrentMissedStreak
+		}
+	}
+
+	// Most recent blocks are listed first
+	for i := len(window.blocks) - 1; i >= 0; i-- {
+		block := window.blocks[i]
+		set := window.sets[block.setEpoch]
+		member := setMemberAt(set, address)
+		if member == nil {
+			continue
+		}
+		if set.totalPower > 0 {
+			signing.ExpectedProposals += float64(member.power) /
+				float64(set.totalPower)
+		}
+
+		nodeBlock := &responses.NodeBlock{Height: block.height,
+			Time: block.time}
+		if bytes.Equal(block.proposer, address) {
+			signing.Proposed++
+			signing.Proposals = append(signing.Proposals, nodeBlock)
+		}
+		_, missed, _ := commitVotes(set, block.commit)
+		for _, m := range missed {
+			if m == member {
+				signing.Misses = append(signing.Misses, nodeBlock)
+			}
+		}
+	}
+	return signing
+}
+
+// GetNodeSigning returns blocks the node with given name signed, missed
+// and proposed over a window of blocks ending before a height, together with
+// number of blocks it was expected to propose. Consensus key of node is
+// mapped to its Tendermint address to find it in commits and proposers.
+func GetNodeSigning(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving name of node from query request
+	nodeName := r.URL.Query().Get("name")
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Node name requested doesn't exist"})
+		return
+	}
+
+	// Retrieving height from query request
+	recvHeight := r.URL.Query().Get("height")
+	height := checkHeight(recvHeight)
+	if height == -1 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, height needs to be a " +
+				"string representing an int!"})
+		return
+	}
+
+	// Retrieving number of blocks in window from query request
+	blocks, msg := checkUptimeWindow(r)
+	if len(msg) > 0 {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
+	// Attempt to load connection with node controller client
+	ncConnection, nc := loadNodeControllerClient(socket)
+
+	// Close connection once code underneath executes
+	defer ncConnection.Close()
+
+	// Attempt to load connection with consensus client
+	connection, co := loadConsensusClient(socket)
+
+	// Close connection once code underneath executes
+	defer connection.Close()
+
+	// If null object was retrieved send response
+	if nc == nil || co == nil {
+
+		// Stop code here faild to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to establish connection using socket: " +
+				socket})
+		return
+	}
+
+	// Identity of node holds its consensus key
+	ctx := context.Background()
+	status, err := nc.GetStatus(ctx)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Node Status!"})
+
+		lgr.Error.Println("Request at /api/consensus/nodesigning failed "+
+			"to retrieve Node Status : ", err)
+		return
+	}
+
+	from, to, err := windowBounds(ctx, co, height, blocks)
+	var window *commitWindow
+	if err == nil {
+		window, err = loadCommitWindow(ctx, co, from, to)
+	}
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Block Commits!"})
+
+		lgr.Error.Println("Request at /api/consensus/nodesigning failed "+
+			"to retrieve Block Commits : ", err)
+		return
+	}
+
+	lgr.Info.Println("Request at /api/consensus/nodesigning responding " +
+		"with Node Signing!")
+	json.NewEncoder(w).Encode(responses.NodeSigningResponse{
+		Height: height, NodeSigning: nodeSigning(window,
+			status.Identity.Node, status.Identity.Consensus)})
+}
@@ -0,0 +1,65 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	hdl "github.com/SimplyVC/oasis_api_server/src/handlers"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+)
+
+func Test_GetNodeSigning_BadNode(t *testing.T) {
+	rr := serveRequest(hdl.GetNodeSigning, "/api/consensus/nodesigning",
+		map[string]string{"name": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Node name requested doesn't exist"}`)
+}
+
+func Test_GetNodeSigning_InvalidHeight(t *testing.T) {
+	rr := serveRequest(hdl.GetNodeSigning, "/api/consensus/nodesigning",
+		map[string]string{"name": "Oasis_Local", "height": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, height `+
+		`needs to be a string representing an int!"}`)
+}
+
+func Test_GetNodeSigning_InvalidBlocks(t *testing.T) {
+	rr := serveRequest(hdl.GetNodeSigning, "/api/consensus/nodesigning",
+		map[string]string{"name": "Oasis_Local", "blocks": "0"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, blocks `+
+		`needs to be a number between 1 and 1000!"}`)
+}
+
+func Test_GetNodeSigning(t *testing.T) {
+	rr := serveRequest(hdl.GetNodeSigning, "/api/consensus/nodesigning",
+		map[string]string{"name": "Oasis_Local", "blocks": "10"})
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	signing := &responses.NodeSigningResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), signing)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	result := signing.NodeSigning
+	if result == nil || result.Blocks != 10 ||
+		result.ToHeight-result.FromHeight+1 != result.Blocks {
+		t.Fatalf("handler returned unexpected body: got %v",
+			rr.Body.String())
+	}
+	if result.Signed+result.Missed != result.Expected {
+		t.Errorf("handler returned wrong tally: got %v signed and %v "+
+			"missed of %v expected", result.Signed, result.Missed,
+			result.Expected)
+	}
+	if int64(len(result.Misses)) != result.Missed {
+		t.Errorf("handler returned wrong misses: got %v want %v",
+			len(result.Misses), result.Missed)
+	}
+	if int64(len(result.Proposals)) != result.Proposed {
+		t.Errorf("handler returned wrong proposals: got %v want %v",
+			len(result.Proposals), result.Proposed)
+	}
+}
@@ -0,0 +1,500 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	common_signature "github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+// Settings used for Server-Sent Events streams
+const (
+	// Period at which comments are sent to keep connection alive
+	sseHeartbeatPeriod = 15 * time.Second
+
+	// Number of events queued for a client
+	sseSendBuffer = 256
+
+	// Time an event waits on a full queue before client is considered slow
+	sseSendTimeout = 30 * time.Second
+
+	// Maximum number of heights backfilled when a stream is resumed
+	sseMaxBackfill = 1000
+
+	// Reconnection delay advised to clients in milliseconds
+	sseRetry = 5000
+)
+
+// sseMessage is a single event sent to a Server-Sent Events client
+type sseMessage struct {
+	id    eventID
+	event string
+	data  interface{}
+}
+
+// WatchStakingEvents streams staking events of node as Server-Sent Events.
+// Events can be filtered by kind and by address taking part in them and a
+// stream can be resumed from the last event the client received.
+func WatchStakingEvents(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving name of node from query request
+	nodeName := r.URL.Query().Get("name")
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Node name requested doesn't exist"})
+		return
+	}
+
+	// Retrieving kinds of events to filter on from query request
+	kinds, err := checkEventKinds(r.URL.Query().Get("kind"),
+		stakingEventKinds)
+	if err != nil {
+		lgr.Error.Println("Request at /api/staking/watchevents failed, ",
+			err)
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, kind needs to be a comma " +
+				"separated list of " + strings.Join(stakingEventKinds, ", ") +
+				"!"})
+		return
+	}
+
+	// Retrieving optional address to filter on from query request
+	var address staking.Address
+	addressQuery := r.URL.Query().Get("address")
+	if len(addressQuery) != 0 {
+		err := address.UnmarshalText([]byte(addressQuery))
+		if err != nil {
+			lgr.Error.Println("Failed to UnmarshalText into Address", err)
+			json.NewEncoder(w).Encode(responses.ErrorResponse{
+				Error: "Failed to UnmarshalText into Address."})
+			return
+		}
+	}
+
+	// Retrieving last event received by client if it's resuming
+	last, err := lastEventID(r)
+	if err != nil {
+		lgr.Error.Println("Request at /api/staking/watchevents failed, ",
+			err)
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, Last-Event-ID needs to be of " +
+				"form height-index!"})
+		return
+	}
+
+	filter := func(ev *staking.Event) bool {
+		if len(kinds) != 0 && !kinds[stakingEventKind(ev)] {
+			return false
+		}
+		return len(addressQuery) == 0 || stakingEventInvolves(ev, address)
+	}
+
+	streamEvents(w, r, "/api/staking/watchevents",
+		"staking events of "+nodeName,
+		func(ctx context.Context, out chan<- sseMessage, reset func()) error {
+			return subscribeStakingEvents(ctx, socket, &last, filter, out,
+				reset)
+		})
+}
+
+// WatchRegistryEvents streams registry events of node as Server-Sent Events.
+// Events can be filtered by kind and by entity or node taking part in them
+// and a stream can be resumed from the last event the client received.
+func WatchRegistryEvents(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving name of node from query request
+	nodeName := r.URL.Query().Get("name")
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Node name requested doesn't exist"})
+		return
+	}
+
+	// Retrieving kinds of events to filter on from query request
+	kinds, err := checkEventKinds(r.URL.Query().Get("kind"),
+		registryEventKinds)
+	if err != nil {
+		lgr.Error.Println("Request at /api/registry/watchevents failed, ",
+			err)
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, kind needs to be a comma " +
+				"separated list of " + strings.Join(registryEventKinds, ", ") +
+				"!"})
+		return
+	}
+
+	// Retrieving optional entity or node ID to filter on from query request
+	var pubKey common_signature.PublicKey
+	entityQuery := r.URL.Query().Get("entity")
+	if len(entityQuery) != 0 {
+		err := pubKey.UnmarshalText([]byte(entityQuery))
+		if err != nil {
+			lgr.Error.Println("Failed to UnmarshalText into Public Key", err)
+			json.NewEncoder(w).Encode(responses.ErrorResponse{
+				Error: "Failed to UnmarshalText into Public Key."})
+			return
+		}
+	}
+
+	// Retrieving last event received by client if it's resuming
+	last, err := lastEventID(r)
+	if err != nil {
+		lgr.Error.Println("Request at /api/registry/watchevents failed, ",
+			err)
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, Last-Event-ID needs to be of " +
+				"form height-index!"})
+		return
+	}
+
+	filter := func(ev *registry.Event) bool {
+		if len(kinds) != 0 && !kinds[registryEventKind(ev)] {
+			return false
+		}
+		return len(entityQuery) == 0 || registryEventInvolves(ev, pubKey)
+	}
+
+	streamEvents(w, r, "/api/registry/watchevents",
+		"registry events of "+nodeName,
+		func(ctx context.Context, out chan<- sseMessage, reset func()) error {
+			return subscribeRegistryEvents(ctx, socket, &last, filter, out,
+				reset)
+		})
+}
+
+// lastEventID retrieves ID of last event received by client, sent either in
+// Last-Event-ID header when reconnecting or in last_event_id query.
+func lastEventID(r *http.Request) (eventID, error) {
+	recvID := r.Header.Get("Last-Event-ID")
+	if len(recvID) == 0 {
+		recvID = r.URL.Query().Get("last_event_id")
+	}
+	if len(recvID) == 0 {
+		return eventID{}, nil
+	}
+	return parseEventID(recvID)
+}
+
+// streamEvents writes messages produced by subscribe as Server-Sent Events
+// until client disconnects, resubscribing whenever subscription fails.
+func streamEvents(w http.ResponseWriter, r *http.Request, endpoint string,
+	stream string, subscribe func(ctx context.Context,
+		out chan<- sseMessage, reset func()) error) {
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		lgr.Error.Println("Request at " + endpoint + " failed, streaming " +
+			"is not supported by connection!")
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Streaming is not supported!"})
+		return
+	}
+
+	// Replace JSON header with event stream headers, asking proxies not to
+	// buffer the stream
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry)
+	flusher.Flush()
+
+	// Request context is cancelled once client disconnects
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	messages := make(chan sseMessage, sseSendBuffer)
+	done := make(chan error, 1)
+	go func() {
+		done <- keepSubscribed(ctx, stream,
+			func(ctx context.Context, reset func()) error {
+				return subscribe(ctx, messages, reset)
+			})
+	}()
+
+	lgr.Info.Println("Request at " + endpoint + " subscribed to " + stream)
+
+	heartbeat := time.NewTicker(sseHeartbeatPeriod)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case msg := <-messages:
+			data, err := json.Marshal(msg.data)
+			if err != nil {
+				lgr.Error.Println("Request at "+endpoint+" failed to "+
+					"Marshal event "+msg.id.String()+" : ", err)
+				continue
+			}
+			_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n",
+				msg.id, msg.event, data)
+			if err != nil {
+				lgr.Warning.Println("Request at "+endpoint+" failed to "+
+					"send event : ", err)
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case err := <-done:
+
+			// Let client know why it was dropped if it was too slow
+			if errors.Is(err, errSubscriberTooSlow) {
+				lgr.Warning.Println("Request at " + endpoint + " dropped " +
+					"client that is not keeping up with events!")
+				data, _ := json.Marshal(responses.ErrorResponse{
+					Error: "Client is not keeping up with events"})
+				fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+				flusher.Flush()
+			}
+			lgr.Info.Println("Request at " + endpoint + " unsubscribed " +
+				"from " + stream)
+			return
+		}
+	}
+}
+
+// queueEvent queues message for client, giving up if client doesn't make
+// room for it in time.
+func queueEvent(ctx context.Context, out chan<- sseMessage,
+	msg sseMessage) error {
+
+	select {
+	case out <- msg:
+		return nil
+	default:
+	}
+
+	timer := time.NewTimer(sseSendTimeout)
+	defer timer.Stop()
+
+	select {
+	case out <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return errSubscriberTooSlow
+	}
+}
+
+// backfillEvents emits events at every height following the last event
+// up to the latest height so that resumed streams don't miss any events.
+// emitHeight emits all events at height with an index greater than after.
+func backfillEvents(ctx context.Context, co consensus.ClientBackend,
+	last *eventID, emitHeight func(height int64, after int) error) error {
+
+	// Nothing to backfill if stream isn't being resumed
+	if last.height <= 0 {
+		return nil
+	}
+
+	status, err := co.GetStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	from, after := last.height, last.index
+	if from < status.LastRetainedHeight {
+		lgr.Warning.Printf("Events before height %d were pruned by node, "+
+			"backfilling from there", status.LastRetainedHeight)
+		from, after = status.LastRetainedHeight, -1
+	}
+	if status.LatestHeight-from > sseMaxBackfill {
+		lgr.Warning.Printf("Backfilling only last %d of %d missed heights",
+			sseMaxBackfill, status.LatestHeight-from)
+		from, after = status.LatestHeight-sseMaxBackfill, -1
+	}
+
+	for height := from; height <= status.LatestHeight; height++ {
+		if err := emitHeight(height, after); err != nil {
+			return err
+		}
+		after = -1
+	}
+	return nil
+}
+
+// subscribeStakingEvents watches staking events of node at socket and
+// queues the ones passing filter until the stream ends. Events following
+// the last event are backfilled first.
+func subscribeStakingEvents(ctx context.Context, socket string,
+	last *eventID, filter func(*staking.Event) bool,
+	out chan<- sseMessage, reset func()) error {
+
+	// Attempt to load connection with consensus client
+	connection, co := loadConsensusClient(socket)
+	if co == nil {
+		return fmt.Errorf("failed to establish connection using socket: %s",
+			socket)
+	}
+
+	// Close connection once subscription ends
+	defer connection.Close()
+	so := co.Staking()
+
+	// Subscribe before backfilling so that no events are missed in between
+	evCh, sub, err := so.WatchEvents(ctx)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	emitHeight := func(height int64, after int) error {
+		events, err := so.GetEvents(ctx, height)
+		if err != nil {
+			return err
+		}
+		for i, ev := range events {
+			if i <= after {
+				continue
+			}
+			id := eventID{height: height, index: i}
+			if filter(ev) {
+				err := queueEvent(ctx, out, sseMessage{
+					id:    id,
+					event: stakingEventKind(ev),
+					data:  responses.StakingEventResponse{StakingEvent: ev},
+				})
+				if err != nil {
+					return err
+				}
+			}
+			*last = id
+		}
+		*last = eventID{height: height, index: len(events) - 1}
+		return nil
+	}
+
+	if err := backfillEvents(ctx, co, last, emitHeight); err != nil {
+		return err
+	}
+
+	// Events of a height are retrieved together when the first of them
+	// arrives, so they are numbered the same way as backfilled ones
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-evCh:
+			if !ok {
+				return errors.New("staking event stream closed by node")
+			}
+			reset()
+
+			// Skip heights that were already backfilled or sent
+			if ev.Height <= last.height {
+				continue
+			}
+			if err := emitHeight(ev.Height, -1); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// subscribeRegistryEvents queues registry events of node at socket that
+// pass filter until the stream ends. Registry backend has no event stream
+// so events are retrieved for each new block. Events following the last
+// event are backfilled first.
+func subscribeRegistryEvents(ctx context.Context, socket string,
+	last *eventID, filter func(*registry.Event) bool,
+	out chan<- sseMessage, reset func()) error {
+
+	// Attempt to load connection with consensus client
+	connection, co := loadConsensusClient(socket)
+	if co == nil {
+		return fmt.Errorf("failed to establish connection using socket: %s",
+			socket)
+	}
+
+	// Close connection once subscription ends
+	defer connection.Close()
+	ro := co.Registry()
+
+	// Subscribe before backfilling so that no blocks are missed in between
+	blkCh, sub, err := co.WatchBlocks(ctx)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	emitHeight := func(height int64, after int) error {
+		events, err := ro.GetEvents(ctx, height)
+		if err != nil {
+			return err
+		}
+		for i, ev := range events {
+			if i <= after {
+				continue
+			}
+			id := eventID{height: height, index: i}
+			if filter(ev) {
+				err := queueEvent(ctx, out, sseMessage{
+					id:    id,
+					event: registryEventKind(ev),
+					data:  responses.RegistryEventResponse{Event: ev},
+				})
+				if err != nil {
+					return err
+				}
+			}
+			*last = id
+		}
+		*last = eventID{height: height, index: len(events) - 1}
+		return nil
+	}
+
+	if err := backfillEvents(ctx, co, last, emitHeight); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case blk, ok := <-blkCh:
+			if !ok {
+				return errors.New("block stream closed by node")
+			}
+			reset()
+
+			// Skip heights that were already backfilled or sent
+			var err error
+			switch {
+			case blk.Height > last.height:
+				err = emitHeight(blk.Height, -1)
+			case blk.Height == last.height:
+				err = emitHeight(blk.Height, last.index)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
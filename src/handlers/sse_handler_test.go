@@ -0,0 +1,182 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	hdl "github.com/SimplyVC/oasis_api_server/src/handlers"
+)
+
+func Test_WatchStakingEvents_BadNode(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/staking/watchevents", nil)
+	q := req.URL.Query()
+	q.Add("name", "Unicorn")
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.WatchStakingEvents)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Node name requested doesn't exist"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_WatchStakingEvents_InvalidKind(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/staking/watchevents", nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	q.Add("kind", "transfer,Unicorn")
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.WatchStakingEvents)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Unexpected value found, kind needs to be a comma separated list of transfer, burn, add_escrow, take_escrow, reclaim_escrow, allowance_change!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_WatchStakingEvents_InvalidLastEventID(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/staking/watchevents", nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	req.URL.RawQuery = q.Encode()
+	req.Header.Set("Last-Event-ID", "Unicorn")
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.WatchStakingEvents)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Unexpected value found, Last-Event-ID needs to be of form height-index!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_WatchStakingEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(hdl.WatchStakingEvents))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET",
+		server.URL+"/api/staking/watchevents?name=Oasis_Local", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to connect to event stream : %v", err)
+	}
+	defer resp.Body.Close()
+
+	if contentType := resp.Header.Get("Content-Type"); contentType !=
+		"text/event-stream" {
+		t.Errorf("handler returned wrong content type: got %v want %v",
+			contentType, "text/event-stream")
+	}
+
+	expected := "retry: 5000"
+
+	line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+	if strings.TrimSpace(line) != expected {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			line, expected)
+	}
+}
+
+func Test_WatchRegistryEvents_BadNode(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/registry/watchevents", nil)
+	q := req.URL.Query()
+	q.Add("name", "Unicorn")
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.WatchRegistryEvents)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Node name requested doesn't exist"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_WatchRegistryEvents_InvalidEntity(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/registry/watchevents", nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	q.Add("entity", "Unicorn")
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.WatchRegistryEvents)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Failed to UnmarshalText into Public Key."}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_WatchRegistryEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(hdl.WatchRegistryEvents))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET",
+		server.URL+"/api/registry/watchevents?name=Oasis_Local&kind=node",
+		nil)
+	req.Header.Set("Last-Event-ID", "1-0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to connect to event stream : %v", err)
+	}
+	defer resp.Body.Close()
+
+	expected := "retry: 5000"
+
+	line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+	if strings.TrimSpace(line) != expected {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			line, expected)
+	}
+}
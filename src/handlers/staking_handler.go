@@ -81,7 +81,8 @@ func GetTotalSupply(w http.ResponseWriter, r *http.Request) {
 
 	lgr.Info.Println("Request at /api/staking/totalsupply responding with " +
 		"TotalSupply!")
-	json.NewEncoder(w).Encode(responses.QuantityResponse{Quantity: totalSupply})
+	json.NewEncoder(w).Encode(responses.QuantityResponse{
+		Height: height, Quantity: totalSupply})
 }
 
 // GetCommonPool returns common pool balance at block height
@@ -141,7 +142,8 @@ func GetCommonPool(w http.ResponseWriter, r *http.Request) {
 
 	lgr.Info.Println("Request at /api/staking/commonpool responding with " +
 		"Common Pool!")
-	json.NewEncoder(w).Encode(responses.QuantityResponse{Quantity: commonPool})
+	json.NewEncoder(w).Encode(responses.QuantityResponse{
+		Height: height, Quantity: commonPool})
 }
 
 
@@ -202,8 +204,8 @@ func GetLastBlockFees(w http.ResponseWriter, r *http.Request) {
 
 	lgr.Info.Println("Request at /api/staking/lastblockfees responding with" +
 		" latest block fees!")
-	json.NewEncoder(w).Encode(responses.QuantityResponse{Quantity: 
-		lastestBlockFees})
+	json.NewEncoder(w).Encode(responses.QuantityResponse{
+		Height: height, Quantity: lastestBlockFees})
 }
 
 // GetStakingStateToGenesis returns state of genesis file of staking client
@@ -234,6 +236,15 @@ func GetStakingStateToGenesis(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Retrieving delivery options of response from query request
+	opts, msg := checkStreamOptions(r)
+	if len(msg) > 0 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
 	// Attempt to load connection with staking client
 	connection, so := loadStakingClient(socket)
 
@@ -263,8 +274,10 @@ func GetStakingStateToGenesis(w http.ResponseWriter, r *http.Request) {
 	lgr.Info.Println(
 		"Request at /api/staking/genesis responding with Staking " +
 			"Genesis State!")
-	json.NewEncoder(w).Encode(responses.StakingGenesisResponse{
-		GenesisStaking: genesisStaking})
+	streamJSON(w, "/api/staking/genesis",
+		"staking_genesis_"+streamHeightName(height)+".json", opts,
+		responses.StakingGenesisResponse{Height: height,
+			GenesisStaking: genesisStaking})
 }
 
 // GetThreshold returns specific staking threshold by kind.
@@ -338,7 +351,8 @@ func GetThreshold(w http.ResponseWriter, r *http.Request) {
 	// Responding with threshold quantity retrieved
 	lgr.Info.Println(
 		"Request at /api/staking/threshold responding with Threshold!")
-	json.NewEncoder(w).Encode(responses.QuantityResponse{Quantity: threshold})
+	json.NewEncoder(w).Encode(responses.QuantityResponse{
+		Height: height, Quantity: threshold})
 }
 
 // GetAddresses returns IDs of all accounts with non-zero general balance
@@ -398,8 +412,8 @@ func GetAddresses(w http.ResponseWriter, r *http.Request) {
 	// Respond with array of all accounts
 	lgr.Info.Println("Request at /api/staking/addresses responding with " +
 		"Addresses!")
-	json.NewEncoder(w).Encode(responses.AllAddressesResponse{AllAddresses: 
-		addresses})
+	json.NewEncoder(w).Encode(responses.AllAddressesResponse{
+		Height: height, AllAddresses: addresses})
 }
 
 // GetAddressFromPublicKey returns a staking address from a given public key
@@ -501,7 +515,16 @@ func GetConsensusParameters(w http.ResponseWriter, r *http.Request) {
 	lgr.Info.Println("Request at /api/staking/consensusparameters responding " +
 		"with Addresses!")
 	json.NewEncoder(w).Encode(responses.ConsensusParametersResponse{
-		ConsensusParameters: consensusParameters})
+		Height: height, ConsensusParameters: consensusParameters})
+}
+
+// accountAt returns account of address at height
+func accountAt(ctx context.Context, so staking.Backend,
+	address staking.Address, height int64) (*staking.Account, error) {
+
+	// Create an owner query to be able to retrieve data with regards to account
+	query := staking.OwnerQuery{Height: height, Owner: address}
+	return so.Account(ctx, &query)
 }
 
 // GetAccount returns the account descriptor for the given account.
@@ -569,11 +592,8 @@ func GetAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create an owner query to be able to retrieve data with regards to account
-	query := staking.OwnerQuery{Height: height, Owner: address}
-
-	// Retrieve account information using created query
-	account, err := so.Account(context.Background(), &query)
+	// Retrieve account information at height
+	account, err := accountAt(context.Background(), so, address, height)
 	if err != nil {
 		json.NewEncoder(w).Encode(responses.ErrorResponse{
 			Error: "Failed to get Account!"})
@@ -586,7 +606,8 @@ func GetAccount(w http.ResponseWriter, r *http.Request) {
 	// Return account information for created query
 	lgr.Info.Println("Request at /api/staking/account responding with " +
 		"Account!")
-	json.NewEncoder(w).Encode(responses.AccountResponse{Account: account})
+	json.NewEncoder(w).Encode(responses.AccountResponse{
+		Height: height, Account: account})
 }
 
 // GetDelegations returns list of delegations for given owner
@@ -672,8 +693,8 @@ func GetDelegations(w http.ResponseWriter, r *http.Request) {
 	// Respond with delegations for given account query
 	lgr.Info.Println("Request at /api/staking/delegations responding with " +
 		"delegations!")
-	json.NewEncoder(w).Encode(responses.DelegationsResponse{Delegations:
-		delegations})
+	json.NewEncoder(w).Encode(responses.DelegationsResponse{
+		Height: height, Delegations: delegations})
 }
 
 // GetDebondingDelegations returns list of debonding delegations
@@ -761,7 +782,183 @@ func GetDebondingDelegations(w http.ResponseWriter, r *http.Request) {
 		"Request at /api/staking/debondingdelegations responding with " +
 			"Debonding Delegations!")
 	json.NewEncoder(w).Encode(responses.DebondingDelegationsResponse{
-		DebondingDelegations: debondingDelegations})
+		Height: height, DebondingDelegations: debondingDelegations})
+}
+
+// GetDelegationsFor returns list of outgoing delegations of given owner
+// (delegator) to escrow accounts
+func GetDelegationsFor(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving name of node from query request
+	nodeName := r.URL.Query().Get("name")
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation  {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Node name requested doesn't exist"})
+		return
+	}
+
+	// Retrieving height from query request
+	recvHeight := r.URL.Query().Get("height")
+	height := checkHeight(recvHeight)
+	if height == -1 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, height needs to be a string representing an int!"})
+		return
+	}
+
+	var address staking.Address
+	addressQuery := r.URL.Query().Get("address")
+	if len(addressQuery) == 0 {
+
+		// Stop code here no need to establish connection and reply
+		lgr.Warning.Println(
+			"Request at /api/staking/delegationsfor failed, address can't be " +
+				"empty!")
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "address can't be empty!"})
+		return
+	}
+
+	// Unmarshal text into public key object
+	err := address.UnmarshalText([]byte(addressQuery))
+	if err != nil {
+		lgr.Error.Println("Failed to UnmarshalText into Address", err)
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to UnmarshalText into Address."})
+		return
+	}
+
+	// Attempt to load connection with staking client
+	connection, so := loadStakingClient(socket)
+
+	// Close connection once code underneath executes
+	defer connection.Close()
+
+	// If null object was retrieved send response
+	if so == nil {
+
+		// Stop code here faild to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to establish connection using socket : " + socket})
+		return
+	}
+
+	// Create an owner query to be able to retrieve data with regards to account
+	query := staking.OwnerQuery{Height: height, Owner: address}
+
+	// Return outgoing delegations for given account query
+	delegations, err := so.DelegationsFor(context.Background(), &query)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to get Delegations!"})
+
+		lgr.Error.Println(
+			"Request at /api/staking/delegationsfor failed to retrieve "+
+				"Delegations : ", err)
+		return
+	}
+
+	// Respond with delegations for given account query
+	lgr.Info.Println("Request at /api/staking/delegationsfor responding with " +
+		"delegations!")
+	json.NewEncoder(w).Encode(responses.DelegationsResponse{
+		Height: height, Delegations: delegations})
+}
+
+// GetDebondingDelegationsFor returns list of outgoing debonding delegations
+// of given owner (delegator) from escrow accounts
+func GetDebondingDelegationsFor(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving name of node from query request
+	nodeName := r.URL.Query().Get("name")
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation  {
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Node name requested doesn't exist"})
+		return
+	}
+
+	// Retrieving height from query request
+	recvHeight := r.URL.Query().Get("height")
+	height := checkHeight(recvHeight)
+	if height == -1 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, height needs to be a string representing an int!"})
+		return
+	}
+
+	var address staking.Address
+	addressQuery := r.URL.Query().Get("address")
+	if len(addressQuery) == 0 {
+
+		// Stop code here no need to establish connection and reply
+		lgr.Warning.Println(
+			"Request at /api/staking/debondingdelegationsfor failed, address can't be " +
+				"empty!")
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "address can't be empty!"})
+		return
+	}
+
+	// Unmarshal text into public key object
+	err := address.UnmarshalText([]byte(addressQuery))
+	if err != nil {
+		lgr.Error.Println("Failed to UnmarshalText into Address", err)
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to UnmarshalText into Address."})
+		return
+	}
+
+	// Attempt to load connection with staking client
+	connection, so := loadStakingClient(socket)
+
+	// Close connection once code underneath executes
+	defer connection.Close()
+
+	// If null object was retrieved send response
+	if so == nil {
+
+		// Stop code here faild to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to establish connection using socket : " + socket})
+		return
+	}
+
+	// Query created to retrieved Debonding Delegations for an account
+	query := staking.OwnerQuery{Height: height, Owner: address}
+
+	// Retrieving debonding delegations for an account using above query
+	debondingDelegations, err := so.DebondingDelegationsFor(
+		context.Background(), &query)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to get Debonding Delegations!"})
+		lgr.Error.Println(
+			"Request at /api/staking/debondingdelegationsfor failed to retrieve"+
+				" Debonding Delegations : ", err)
+		return
+	}
+
+	// Responding with debonding delegations for given accounts
+	lgr.Info.Println(
+		"Request at /api/staking/debondingdelegationsfor responding with " +
+			"Debonding Delegations!")
+	json.NewEncoder(w).Encode(responses.DebondingDelegationsResponse{
+		Height: height, DebondingDelegations: debondingDelegations})
 }
 
 // GetEvents returns events at a specific height.
@@ -820,5 +1017,6 @@ func GetEvents(w http.ResponseWriter, r *http.Request) {
 	// Respond with array of all accounts
 	lgr.Info.Println("Request at /api/staking/events responding with" +
 		" Events!")
-	json.NewEncoder(w).Encode(responses.StakingEvents{StakingEvents: events})
+	json.NewEncoder(w).Encode(responses.StakingEvents{
+		Height: height, StakingEvents: events})
 }
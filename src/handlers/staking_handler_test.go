@@ -215,6 +215,29 @@ func Test_GetStakingStateToGenesis_InvalidHeight(t *testing.T) {
 	}
 }
 
+func Test_GetStakingStateToGenesis_InvalidDownload(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/staking/genesis", nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	q.Add("download", "Unicorn")
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetStakingStateToGenesis)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Unexpected value found, download needs to be either true or false!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
 func Test_GetStakingStateToGenesis(t *testing.T) {
 	req, _ := http.NewRequest("GET", "/api/staking/genesis", nil)
 	q := req.URL.Query()
@@ -641,6 +664,164 @@ func Test_GetDebondingDelegations(t *testing.T) {
 	}
 }
 
+func Test_GetDelegationsFor_BadNode(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/staking/delegationsfor", nil)
+	q := req.URL.Query()
+	q.Add("name", "Unicorn")
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetDelegationsFor)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Node name requested doesn't exist"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_GetDelegationsFor_InvalidHeight(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/staking/delegationsfor", nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	q.Add("height", "Unicorn")
+
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetDelegationsFor)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Unexpected value found, height needs to be a string representing an int!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_GetDelegationsFor(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/staking/delegationsfor", nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	q.Add("address", "oasis1qqqf342r78nz05dq2pa3wzh0w54k3ea49u6rqdhv")
+
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetDelegationsFor)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := "result"
+
+	delegations := &responses.DelegationsResponse{
+		Delegations: map[staking_api.Address]*staking_api.Delegation{},
+	}
+
+	err := json.Unmarshal([]byte(rr.Body.String()), delegations)
+	if err != nil {
+		t.Errorf("Failed to unmarshall data")
+	}
+
+	if strings.Contains(strings.TrimSpace(rr.Body.String()), expected) != true {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			strings.TrimSpace(rr.Body.String()), expected)
+	}
+}
+
+func Test_GetDebondingDelegationsFor_BadNode(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/staking/debondingdelegationsfor", nil)
+	q := req.URL.Query()
+	q.Add("name", "Unicorn")
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetDebondingDelegationsFor)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Node name requested doesn't exist"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_GetDebondingDelegationsFor_InvalidHeight(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/staking/debondingdelegationsfor", nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	q.Add("height", "Unicorn")
+
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetDebondingDelegationsFor)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Unexpected value found, height needs to be a string representing an int!"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_GetDebondingDelegationsFor(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/staking/debondingdelegationsfor", nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	q.Add("address", "oasis1qqqf342r78nz05dq2pa3wzh0w54k3ea49u6rqdhv")
+
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.GetDebondingDelegationsFor)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := "result"
+
+	debondingDelegations := &responses.DebondingDelegationsResponse{
+		DebondingDelegations: map[staking_api.Address][]*staking_api.DebondingDelegation{},
+	}
+
+	err := json.Unmarshal([]byte(rr.Body.String()), debondingDelegations)
+	if err != nil {
+		t.Errorf("Failed to unmarshall data")
+	}
+
+	if strings.Contains(strings.TrimSpace(rr.Body.String()), expected) != true {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			strings.TrimSpace(rr.Body.String()), expected)
+	}
+}
+
 func Test_GetEvents_BadNode(t *testing.T) {
 	req, _ := http.NewRequest("GET", "/api/staking/events", nil)
 	q := req.URL.Query()
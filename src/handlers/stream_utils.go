@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"strconv"
+
+	"github.com/SimplyVC/oasis_api_server/src/jsonstream"
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+)
+
+// Depth up to which streamed responses are written element by element
+const streamMaxDepth = 5
+
+// Trailer carrying hex encoded SHA-256 of uncompressed downloaded JSON
+const contentHashTrailer = "X-Content-SHA256"
+
+// streamOptions holds how a streamed response should be delivered
+type streamOptions struct {
+	gzip     bool
+	download bool
+}
+
+// checkStreamOptions parses gzip and download query parameters, returning
+// an error message if any of them is invalid.
+func checkStreamOptions(r *http.Request) (streamOptions, string) {
+	opts := streamOptions{}
+	var err error
+
+	if recvGzip := r.URL.Query().Get("gzip"); len(recvGzip) > 0 {
+		opts.gzip, err = strconv.ParseBool(recvGzip)
+		if err != nil {
+			return opts, "Unexpected value found, gzip needs to be " +
+				"either true or false!"
+		}
+	}
+	if recvDownload := r.URL.Query().Get("download"); len(recvDownload) > 0 {
+		opts.download, err = strconv.ParseBool(recvDownload)
+		if err != nil {
+			return opts, "Unexpected value found, download needs to be " +
+				"either true or false!"
+		}
+	}
+	return opts, ""
+}
+
+// streamWriter writes response body, optionally compressing it and hashing
+// its uncompressed content, flushing each chunk to the client.
+type streamWriter struct {
+	w    http.ResponseWriter
+	gz   *gzip.Writer
+	hash hash.Hash
+}
+
+// Write writes uncompressed data
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	if sw.hash != nil {
+		sw.hash.Write(p)
+	}
+	if sw.gz != nil {
+		return sw.gz.Write(p)
+	}
+	return sw.w.Write(p)
+}
+
+// Flush sends data written so far to the client
+func (sw *streamWriter) Flush() error {
+	if sw.gz != nil {
+		if err := sw.gz.Flush(); err != nil {
+			return err
+		}
+	}
+	if f, ok := sw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// streamJSON writes v as JSON using chunked transfer without building the
+// whole response in memory. If requested the response is gzipped and
+// offered as a file download named filename with a content hash trailer.
+func streamJSON(w http.ResponseWriter, endpoint string, filename string,
+	opts streamOptions, v interface{}) {
+
+	sw := &streamWriter{w: w}
+	if opts.download {
+		sw.hash = sha256.New()
+		w.Header().Set("Content-Disposition",
+			"attachment; filename=\""+filename+"\"")
+		w.Header().Set("Trailer", contentHashTrailer)
+	}
+	if opts.gzip {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Add("Vary", "Accept-Encoding")
+		sw.gz = gzip.NewWriter(w)
+	}
+	w.WriteHeader(http.StatusOK)
+
+	err := jsonstream.NewEncoder(sw, streamMaxDepth).Encode(v)
+	if err == nil && sw.gz != nil {
+		err = sw.gz.Close()
+	}
+	if err != nil {
+
+		// Headers are already sent so the client is left with a
+		// truncated body and no content hash
+		lgr.Error.Println("Request at "+endpoint+" failed to stream "+
+			"response : ", err)
+		return
+	}
+
+	if sw.hash != nil {
+		w.Header().Set(contentHashTrailer,
+			hex.EncodeToString(sw.hash.Sum(nil)))
+	}
+}
+
+// streamHeightName returns height as used in names of downloaded files
+func streamHeightName(height int64) string {
+	if height == consensus.HeightLatest {
+		return "latest"
+	}
+	return strconv.FormatInt(height, 10)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+)
+
+// errSubscriberTooSlow is returned when a subscriber can't keep up with the
+// stream it is subscribed to and has to be dropped.
+var errSubscriberTooSlow = errors.New("subscriber is not keeping up " +
+	"with stream")
+
+// keepSubscribed runs subscribe until context is cancelled, resubscribing
+// with an exponential backoff every time the stream to the node drops.
+// subscribe is expected to call reset once it receives data so that the
+// backoff starts over after every healthy subscription.
+func keepSubscribed(ctx context.Context, stream string,
+	subscribe func(ctx context.Context, reset func()) error) error {
+
+	// Keep retrying for as long as the subscriber is still connected
+	retry := backoff.NewExponentialBackOff()
+	retry.MaxElapsedTime = 0
+
+	for {
+		err := subscribe(ctx, retry.Reset)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, errSubscriberTooSlow) {
+			return err
+		}
+
+		wait := retry.NextBackOff()
+		lgr.Warning.Printf("Subscription to %s dropped, resubscribing in "+
+			"%s : %v", stream, wait, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+// tokenUnits holds ticker symbol of token and base-10 exponent of its value
+// in base units
+type tokenUnits struct {
+	symbol   string
+	exponent uint8
+}
+
+// getTokenUnits returns ticker symbol and value exponent of token of network
+func getTokenUnits(ctx context.Context, so staking.Backend) (tokenUnits,
+	error) {
+
+	symbol, err := so.TokenSymbol(ctx)
+	if err != nil {
+		return tokenUnits{}, err
+	}
+	exponent, err := so.TokenValueExponent(ctx)
+	if err != nil {
+		return tokenUnits{}, err
+	}
+	return tokenUnits{symbol: symbol, exponent: exponent}, nil
+}
+
+// format formats amount of base units as tokens with all decimals of token
+func (u tokenUnits) format(amount *quantity.Quantity) string {
+	return u.formatInt(amount.ToBigInt())
+}
+
+// formatInt formats signed amount of base units as tokens with all decimals
+// of token
+func (u tokenUnits) formatInt(amount *big.Int) string {
+	sign := ""
+	if amount.Sign() < 0 {
+		sign = "-"
+	}
+	digits := new(big.Int).Abs(amount).String()
+	if u.exponent == 0 {
+		return sign + digits
+	}
+
+	exponent := int(u.exponent)
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	point := len(digits) - exponent
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// percentage returns part as a percentage of whole, or 0 if whole is zero
+func percentage(part *quantity.Quantity, whole *quantity.Quantity) float64 {
+	if whole.IsZero() {
+		return 0
+	}
+	ratio := new(big.Rat).SetFrac(part.ToBigInt(), whole.ToBigInt())
+	value, _ := ratio.Mul(ratio, big.NewRat(100, 1)).Float64()
+	return value
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/oasisprotocol/ed25519"
+
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+	governance "github.com/oasisprotocol/oasis-core/go/governance/api"
+	keymanager "github.com/oasisprotocol/oasis-core/go/keymanager/api"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+const (
+	// Separator between signature context and chain context
+	chainContextSeparator = " for chain "
+
+	// Largest body of a request submitting a transaction
+	maxSubmittedTransactionSize = 1 << 20
+)
+
+// Transaction methods of consensus layer. Some methods such as
+// registry.DeregisterEntity have no body, so they can't be told apart from
+// unknown methods by their registered body type.
+var knownTransactionMethods = map[transaction.MethodName]bool{
+	beacon.MethodPVSSCommit:                true,
+	beacon.MethodPVSSReveal:                true,
+	governance.MethodSubmitProposal:        true,
+	governance.MethodCastVote:              true,
+	keymanager.MethodUpdatePolicy:          true,
+	registry.MethodRegisterEntity:          true,
+	registry.MethodDeregisterEntity:        true,
+	registry.MethodRegisterNode:            true,
+	registry.MethodUnfreezeNode:            true,
+	registry.MethodRegisterRuntime:         true,
+	roothash.MethodExecutorCommit:          true,
+	roothash.MethodExecutorProposerTimeout: true,
+	roothash.MethodEvidence:                true,
+	staking.MethodTransfer:                 true,
+	staking.MethodBurn:                     true,
+	staking.MethodAddEscrow:                true,
+	staking.MethodReclaimEscrow:            true,
+	staking.MethodAmendCommissionSchedule:  true,
+	staking.MethodAllow:                    true,
+	staking.MethodWithdraw:                 true,
+}
+
+// verifyTransaction checks signature of transaction for chain with given
+// context. Signature contexts of oasis-core depend on a single process wide
+// chain context, so message signed is prepared here instead to allow nodes
+// of different chains to be queried.
+func verifyTransaction(chainContext string,
+	signed *transaction.SignedTransaction) bool {
+
+	if signed.Signature.PublicKey.IsBlacklisted() {
+		return false
+	}
+
+	return ed25519.Verify(
+		ed25519.PublicKey(signed.Signature.PublicKey[:]),
+		signingHash(chainContext, signed.Blob),
+		signed.Signature.Signature[:])
+}
+
+// signingContext returns context transactions of chain with given context
+// are signed in
+func signingContext(chainContext string) string {
+	return string(transaction.SignatureContext) + chainContextSeparator +
+		chainContext
+}
+
+// signingHash returns hash of transaction signed by signer of chain with
+// given context
+func signingHash(chainContext string, blob []byte) []byte {
+	h := sha512.New512_256()
+	h.Write([]byte(signingContext(chainContext)))
+	h.Write(blob)
+	return h.Sum(nil)
+}
+
+// decodeTransactionBody decodes body of transaction into type registered
+// for its method, or returns nil if method is unknown.
+func decodeTransactionBody(tx *transaction.Transaction) (interface{}, error) {
+	bodyType := tx.Method.BodyType()
+	if bodyType == nil {
+		return nil, nil
+	}
+
+	body := reflect.New(reflect.TypeOf(bodyType)).Interface()
+	if err := cbor.Unmarshal(tx.Body, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// decodeTransaction decodes raw signed transaction of chain with given
+// context. Transactions which can't be decoded are returned with an error.
+func decodeTransaction(chainContext string,
+	raw []byte) *responses.DecodedTransaction {
+
+	decoded := &responses.DecodedTransaction{
+		Hash: hash.NewFromBytes(raw),
+	}
+
+	var signed transaction.SignedTransaction
+	if err := cbor.Unmarshal(raw, &signed); err != nil {
+		decoded.Error = "Failed to decode signed transaction: " + err.Error()
+		return decoded
+	}
+	decoded.Signer = signed.Signature.PublicKey
+	decoded.SignerAddress = staking.NewAddress(signed.Signature.PublicKey)
+	decoded.ValidSignature = verifyTransaction(chainContext, &signed)
+
+	var tx transaction.Transaction
+	if err := cbor.Unmarshal(signed.Blob, &tx); err != nil {
+		decoded.Error = "Failed to decode transaction: " + err.Error()
+		return decoded
+	}
+	decoded.Nonce = tx.Nonce
+	decoded.Fee = tx.Fee
+	decoded.Method = tx.Method
+
+	body, err := decodeTransactionBody(&tx)
+	switch {
+	case err != nil:
+		decoded.Error = "Failed to decode transaction body: " + err.Error()
+		decoded.RawBody = tx.Body
+	case body == nil:
+		decoded.RawBody = tx.Body
+	default:
+		decoded.Body = body
+	}
+	return decoded
+}
+
+// checkTransactionMethods parses comma separated list of transaction
+// methods, checking that each one is known. An empty list matches every
+// method.
+func checkTransactionMethods(recvMethods string) (
+	map[transaction.MethodName]bool, error) {
+
+	methods := make(map[transaction.MethodName]bool)
+	if len(recvMethods) == 0 {
+		return methods, nil
+	}
+
+	for _, method := range strings.Split(recvMethods, ",") {
+		methodName := transaction.MethodName(strings.TrimSpace(method))
+		if !knownTransactionMethods[methodName] {
+			return nil, fmt.Errorf("unknown transaction method %s", method)
+		}
+		methods[methodName] = true
+	}
+	return methods, nil
+}
+
+// transactionAccounts returns addresses of accounts which signed
+// transaction, are named in its body or take part in staking events it
+// emitted, without duplicates.
+func transactionAccounts(
+	tx *responses.TransactionWithResult) []staking.Address {
+
+	accounts := []staking.Address{}
+	add := func(address staking.Address) {
+		for _, account := range accounts {
+			if account.Equal(address) {
+				return
+			}
+		}
+		accounts = append(accounts, address)
+	}
+
+	// Signer is unknown if signed transaction couldn't be decoded
+	var unknown staking.Address
+	if !tx.Transaction.SignerAddress.Equal(unknown) {
+		add(tx.Transaction.SignerAddress)
+	}
+
+	switch body := tx.Transaction.Body.(type) {
+	case *staking.Transfer:
+		add(body.To)
+	case *staking.Escrow:
+		add(body.Account)
+	case *staking.ReclaimEscrow:
+		add(body.Account)
+	case *staking.Allow:
+		add(body.Beneficiary)
+	case *staking.Withdraw:
+		add(body.From)
+	}
+
+	for _, ev := range tx.Events {
+		if ev.Staking != nil {
+			for _, account := range stakingEventAccounts(ev.Staking) {
+				add(account)
+			}
+		}
+	}
+	return accounts
+}
+
+// transactionInvolves checks whether address signed transaction, is an
+// account named in its body or takes part in staking events it emitted.
+func transactionInvolves(tx *responses.TransactionWithResult,
+	address staking.Address) bool {
+
+	for _, account := range transactionAccounts(tx) {
+		if account.Equal(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// transactionsWithResults decodes transactions of chain with given context,
+// pairing each one with its result
+func transactionsWithResults(chainContext string,
+	txsWithResults *consensus.TransactionsWithResults) (
+	transactions []*responses.TransactionWithResult) {
+
+	transactions = []*responses.TransactionWithResult{}
+	for i, raw := range txsWithResults.Transactions {
+		tx := &responses.TransactionWithResult{
+			Transaction: decodeTransaction(chainContext, raw),
+		}
+		if i < len(txsWithResults.Results) {
+			result := txsWithResults.Results[i]
+			tx.Success = result.IsSuccess()
+			if !tx.Success {
+				tx.Error = &result.Error
+			}
+			tx.Events = result.Events
+		}
+		transactions = append(transactions, tx)
+	}
+	return transactions
+}
+
+// readSignedTransaction reads signed transaction from body of request, CBOR
+// encoded if Content-Type is application/cbor and JSON encoded otherwise.
+func readSignedTransaction(r *http.Request) (*transaction.SignedTransaction,
+	error) {
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, r.Body,
+		maxSubmittedTransactionSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var signed transaction.SignedTransaction
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/cbor" {
+		err = cbor.Unmarshal(body, &signed)
+	} else {
+		err = json.Unmarshal(body, &signed)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(signed.Blob) == 0 {
+		return nil, fmt.Errorf("signed transaction has no body")
+	}
+	return &signed, nil
+}
@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	mint_api "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	"github.com/oasisprotocol/oasis-core/go/consensus/tendermint/crypto"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	tmcrypto "github.com/tendermint/tendermint/crypto"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+const (
+	// Number of blocks in window of uptime if none is given
+	defaultUptimeBlocks = 100
+
+	// Largest number of blocks in window of uptime
+	maxUptimeBlocks = 1000
+
+	// Number of heights after which validators elected by scheduler start
+	// signing, as Tendermint applies validator updates with this delay
+	validatorUpdateDelay = 2
+)
+
+// setMember is a validator of a Tendermint validator set
+type setMember struct {
+	nodeID   signature.PublicKey
+	entityID signature.PublicKey
+	address  tmcrypto.Address
+	power    int64
+}
+
+// validatorSet holds validators signing a height in the order Tendermint
+// lists their signatures in a commit
+type validatorSet struct {
+	members    []*setMember
+	totalPower int64
+}
+
+// windowBlock holds time, proposer and commit of a height in window
+// together with epoch of validator set which signed it
+type windowBlock struct {
+	height   int64
+	time     time.Time
+	proposer tmcrypto.Address
+	commit   *tmtypes.Commit
+	setEpoch beacon.EpochTime
+}
+
+// commitWindow holds blocks of a window of heights and validator sets
+// which signed them
+type commitWindow struct {
+	from   int64
+	to     int64
+	blocks []*windowBlock
+	sets   map[beacon.EpochTime]*validatorSet
+}
+
+// setHeight returns height at which scheduler elected validators signing
+// height
+func setHeight(height int64) int64 {
+	if height-validatorUpdateDelay < 1 {
+		return 1
+	}
+	return height - validatorUpdateDelay
+}
+
+// loadValidatorSet returns validators elected by scheduler at height with
+// their Tendermint addresses, sorted by descending voting power and then
+// by address as Tendermint sorts them
+func loadValidatorSet(ctx context.Context, co consensus.ClientBackend,
+	height int64) (*validatorSet, error) {
+
+	validators, err := co.Scheduler().GetValidators(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &validatorSet{members: make([]*setMember, len(validators))}
+	err = runWorkers(ctx, len(validators), func(ctx context.Context,
+		i int) error {
+
+		node, err := co.Registry().GetNode(ctx, &registry.IDQuery{
+			Height: height, ID: validators[i].ID})
+		if err != nil {
+			return err
+		}
+		set.members[i] = &setMember{
+			nodeID:   node.ID,
+			entityID: node.EntityID,
+			address: crypto.PublicKeyToTendermint(
+				&node.Consensus.ID).Address(),
+			power: validators[i].VotingPower,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(set.members, func(i, j int) bool {
+		a, b := set.members[i], set.members[j]
+		if a.power != b.power {
+			return a.power > b.power
+		}
+		return bytes.Compare(a.address, b.address) < 0
+	})
+	for _, member := range set.members {
+		set.totalPower += member.power
+	}
+	return set, nil
+}
+
+// loadCommitWindow reads blocks of heights from to to and their commits
+// using a bounded pool of workers, together with validator sets which
+// signed them. Commit of a height is held by block following it.
+func loadCommitWindow(ctx context.Context, co consensus.ClientBackend,
+	from int64, to int64) (*commitWindow, error) {
+
+	window := &commitWindow{from: from, to: to,
+		blocks: make([]*windowBlock, to-from+1),
+		sets:   make(map[beacon.EpochTime]*validatorSet)}
+	metas := make([]*mint_api.BlockMeta, len(window.blocks)+1)
+	err := runWorkers(ctx, len(metas), func(ctx context.Context,
+		i int) error {
+
+		blk, err := co.GetBlock(ctx, from+int64(i))
+		if err != nil {
+			return err
+		}
+		meta := &mint_api.BlockMeta{}
+		if err := cbor.Unmarshal(blk.Meta, meta); err != nil {
+			return err
+		}
+		metas[i] = meta
+		if i == len(window.blocks) {
+			return nil
+		}
+
+		epoch, err := co.Beacon().GetEpoch(ctx, setHeight(blk.Height))
+		if err != nil {
+			return err
+		}
+		window.blocks[i] = &windowBlock{height: blk.Height,
+			time: blk.Time, setEpoch: epoch}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, block := range window.blocks {
+		if header := metas[i].Header; header != nil {
+			block.proposer = header.ProposerAddress
+		}
+		block.commit = metas[i+1].LastCommit
+	}
+
+	// Validator set only changes when an epoch starts
+	for _, block := range window.blocks {
+		if window.sets[block.setEpoch] != nil {
+			continue
+		}
+		set, err := loadValidatorSet(ctx, co, setHeight(block.height))
+		if err != nil {
+			return nil, err
+		}
+		window.sets[block.setEpoch] = set
+	}
+	return window, nil
+}
+
+// commitVotes returns members of set which signed and missed commit. Absent
+// signatures carry no address, so they can only be attributed when
+// signatures of commit line up with members of set, otherwise false is
+// returned.
+func commitVotes(set *validatorSet, commit *tmtypes.Commit) ([]*setMember,
+	[]*setMember, bool) {
+
+	if commit == nil || len(commit.Signatures) != len(set.members) {
+		return nil, nil, false
+	}
+	for i, sig := range commit.Signatures {
+		if !sig.Absent() && !bytes.Equal(sig.ValidatorAddress,
+			set.members[i].address) {
+			return nil, nil, false
+		}
+	}
+
+	signed, missed := []*setMember{}, []*setMember{}
+	for i, sig := range commit.Signatures {
+		if sig.Absent() {
+			missed = append(missed, set.members[i])
+		} else {
+			signed = append(signed, set.members[i])
+		}
+	}
+	return signed, missed, true
+}
+
+// uptimePercent returns signed as a percentage of expected, or 0 if nothing
+// was expected
+func uptimePercent(signed int64, expected int64) float64 {
+	if expected == 0 {
+		return 0
+	}
+	return float64(signed) * 100 / float64(expected)
+}
+
+// validatorUptimes tallies signatures of every validator over commits of
+// window, in order of height so that streaks of missed blocks are followed
+func validatorUptimes(window *commitWindow) *responses.ValidatorUptimes {
+	uptimes := &responses.ValidatorUptimes{
+		FromHeight: window.from,
+		ToHeight:   window.to,
+		Blocks:     int64(len(window.blocks)),
+		Validators: []*responses.ValidatorUptime{},
+	}
+
+	tallies := make(map[signature.PublicKey]*responses.ValidatorUptime)
+	tally := func(member *setMember) *responses.ValidatorUptime {
+		uptime := tallies[member.nodeID]
+		if uptime == nil {
+			uptime = &responses.ValidatorUptime{NodeID: member.nodeID,
+				EntityID:          member.entityID,
+				TendermintAddress: member.address}
+			tallies[member.nodeID] = uptime
+			uptimes.Validators = append(uptimes.Validators, uptime)
+		}
+		uptime.Expected++
+		return uptime
+	}
+
+	for _, block := range window.blocks {
+		signed, missed, ok := commitVotes(window.sets[block.setEpoch],
+			block.commit)
+		if !ok {
+			uptimes.Unattributed++
+			continue
+		}
+		for _, member := range signed {
+			uptime := tally(member)
+			uptime.Signed++
+			uptime.CurrentMissedStreak = 0
+		}
+		for _, member := range missed {
+			uptime := tally(member)
+			uptime.Missed++
+			uptime.CurrentMissedStreak++
+			if uptime.CurrentMissedStreak > uptime.LongestMissedStreak {
+				uptime.LongestMissedStreak = uptime.CurrentMissedStreak
+			}
+		}
+	}
+
+	for _, uptime := range uptimes.Validators {
+		uptime.Uptime = uptimePercent(uptime.Signed, uptime.Expected)
+		uptimes.Expected += uptime.Expected
+		uptimes.Signed += uptime.Signed
+		uptimes.Missed += uptime.Missed
+	}
+	uptimes.Uptime = uptimePercent(uptimes.Signed, uptimes.Expected)
+
+	// Validators which missed most blocks come first
+	sort.Slice(uptimes.Validators, func(i, j int) bool {
+		a, b := uptimes.Validators[i], uptimes.Validators[j]
+		if a.Missed != b.Missed {
+			return a.Missed > b.Missed
+		}
+		return bytes.Compare(a.TendermintAddress,
+			b.TendermintAddress) < 0
+	})
+	return uptimes
+}
+
+// checkUptimeWindow parses number of blocks in window of uptime from query
+// request, returning an error message if it is invalid.
+func checkUptimeWindow(r *http.Request) (int64, string) {
+	recvBlocks := r.URL.Query().Get("blocks")
+	if len(recvBlocks) == 0 {
+		return defaultUptimeBlocks, ""
+	}
+	blocks, err := strconv.ParseInt(recvBlocks, 10, 64)
+	if err != nil || blocks < 1 || blocks > maxUptimeBlocks {
+		return 0, "Unexpected value found, blocks needs to be a number " +
+			"between 1 and " + strconv.Itoa(maxUptimeBlocks) + "!"
+	}
+	return blocks, ""
+}
+
+// windowBounds returns heights of window of blocks read from commits held
+// by blocks up to height. As commit of a height is held by block following
+// it, window ends at the block before height, or before latest block when
+// no height is given, so that it only depends on height.
+func windowBounds(ctx context.Context, co consensus.ClientBackend,
+	height int64, blocks int64) (int64, int64, error) {
+
+	if height == consensus.HeightLatest {
+		blk, err := co.GetBlock(ctx, consensus.HeightLatest)
+		if err != nil {
+			return 0, 0, err
+		}
+		height = blk.Height
+	}
+	to := height - 1
+	from := to - blocks + 1
+	if from < 1 {
+		from = 1
+	}
+	return from, to, nil
+}
+
+// GetValidatorUptimes returns number of blocks each validator signed and
+// missed over a window of blocks ending before a height, with its uptime and
+// longest streak of missed blocks, and totals for the whole set. Signatures
+// of a commit are mapped to validators through their Tendermint addresses.
+func GetValidatorUptimes(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving name of node from query request
+	nodeName := r.URL.Query().Get("name")
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Node name requested doesn't exist"})
+		return
+	}
+
+	// Retrieving height from query request
+	recvHeight := r.URL.Query().Get("height")
+	height := checkHeight(recvHeight)
+	if height == -1 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, height needs to be a " +
+				"string representing an int!"})
+		return
+	}
+
+	// Retrieving number of blocks in window from query request
+	blocks, msg := checkUptimeWindow(r)
+	if len(msg) > 0 {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{Error: msg})
+		return
+	}
+
+	// Retrieving validator from query request, all validators are
+	// returned if none is given
+	var nodeID *signature.PublicKey
+	if recvNodeID := r.URL.Query().Get("nodeID"); len(recvNodeID) > 0 {
+		nodeID = &signature.PublicKey{}
+		if err := nodeID.UnmarshalText([]byte(recvNodeID)); err != nil {
+			lgr.Error.Println("Failed to UnmarshalText into PublicKey",
+				err)
+			json.NewEncoder(w).Encode(responses.ErrorResponse{
+				Error: "Failed to UnmarshalText into PublicKey."})
+			return
+		}
+	}
+
+	// Attempt to load connection with consensus client
+	connection, co := loadConsensusClient(socket)
+
+	// Close connection once code underneath executes
+	defer connection.Close()
+
+	// If null object was retrieved send response
+	if co == nil {
+
+		// Stop code here faild to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to establish connection using socket: " +
+				socket})
+		return
+	}
+
+	ctx := context.Background()
+	from, to, err := windowBounds(ctx, co, height, blocks)
+	var window *commitWindow
+	if err == nil {
+		window, err = loadCommitWindow(ctx, co, from, to)
+	}
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Block Commits!"})
+
+		lgr.Error.Println("Request at /api/consensus/validatoruptimes "+
+			"failed to retrieve Block Commits : ", err)
+		return
+	}
+
+	uptimes := validatorUptimes(window)
+	if nodeID != nil {
+		var found []*responses.ValidatorUptime
+		for _, uptime := range uptimes.Validators {
+			if uptime.NodeID.Equal(*nodeID) {
+				found = append(found, uptime)
+			}
+		}
+		if len(found) == 0 {
+			json.NewEncoder(w).Encode(responses.ErrorResponse{
+				Error: "Node requested wasn't a validator in window!"})
+			return
+		}
+		uptimes.Validators = found
+	}
+
+	lgr.Info.Println("Request at /api/consensus/validatoruptimes " +
+		"responding with Validator Uptimes!")
+	json.NewEncoder(w).Encode(responses.ValidatorUptimesResponse{
+		Height: height, ValidatorUptimes: uptimes})
+}
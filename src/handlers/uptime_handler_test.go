@@ -0,0 +1,105 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	hdl "github.com/SimplyVC/oasis_api_server/src/handlers"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+)
+
+func Test_GetValidatorUptimes_BadNode(t *testing.T) {
+	rr := serveRequest(hdl.GetValidatorUptimes,
+		"/api/consensus/validatoruptimes",
+		map[string]string{"name": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Node name requested doesn't exist"}`)
+}
+
+func Test_GetValidatorUptimes_InvalidHeight(t *testing.T) {
+	rr := serveRequest(hdl.GetValidatorUptimes,
+		"/api/consensus/validatoruptimes",
+		map[string]string{"name": "Oasis_Local", "height": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, height `+
+		`needs to be a string representing an int!"}`)
+}
+
+func Test_GetValidatorUptimes_InvalidBlocks(t *testing.T) {
+	rr := serveRequest(hdl.GetValidatorUptimes,
+		"/api/consensus/validatoruptimes",
+		map[string]string{"name": "Oasis_Local", "blocks": "1001"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, blocks `+
+		`needs to be a number between 1 and 1000!"}`)
+}
+
+func Test_GetValidatorUptimes_InvalidNodeID(t *testing.T) {
+	rr := serveRequest(hdl.GetValidatorUptimes,
+		"/api/consensus/validatoruptimes",
+		map[string]string{"name": "Oasis_Local", "nodeID": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Failed to UnmarshalText into `+
+		`PublicKey."}`)
+}
+
+func Test_WindowBounds(t *testing.T) {
+
+	// Window at an explicit height needs no node to be resolved
+	for _, window := range []struct{ height, blocks, from, to int64 }{
+		{100, 10, 90, 99},
+		{100, 1, 99, 99},
+		{5, 10, 1, 4},
+	} {
+		from, to, err := hdl.WindowBounds(context.Background(), nil,
+			window.height, window.blocks)
+		if err != nil || from != window.from || to != window.to {
+			t.Errorf("function returned wrong window at height %v: got "+
+				"%v-%v want %v-%v", window.height, from, to, window.from,
+				window.to)
+		}
+	}
+}
+
+func Test_GetValidatorUptimes(t *testing.T) {
+	rr := serveRequest(hdl.GetValidatorUptimes,
+		"/api/consensus/validatoruptimes",
+		map[string]string{"name": "Oasis_Local", "blocks": "10"})
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	uptimes := &responses.ValidatorUptimesResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), uptimes)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	result := uptimes.ValidatorUptimes
+	if result == nil || result.Blocks != 10 ||
+		result.ToHeight-result.FromHeight+1 != result.Blocks {
+		t.Fatalf("handler returned unexpected body: got %v",
+			rr.Body.String())
+	}
+	checkUptimeTally(t, result)
+}
+
+// checkUptimeTally checks that signed and missed blocks add up to those
+// expected, for each validator and in total
+func checkUptimeTally(t *testing.T, uptimes *responses.ValidatorUptimes) {
+	signed, missed := int64(0), int64(0)
+	for _, uptime := range uptimes.Validators {
+		if uptime.Signed+uptime.Missed != uptime.Expected {
+			t.Errorf("handler returned wrong tally of %v: got %v signed "+
+				"and %v missed of %v expected", uptime.NodeID,
+				uptime.Signed, uptime.Missed, uptime.Expected)
+		}
+		signed += uptime.Signed
+		missed += uptime.Missed
+	}
+	if signed != uptimes.Signed || missed != uptimes.Missed ||
+		signed+missed != uptimes.Expected {
+		t.Errorf("handler returned wrong total tally: got %v signed and "+
+			"%v missed of %v expected", uptimes.Signed, uptimes.Missed,
+			uptimes.Expected)
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/SimplyVC/oasis_api_server/src/config"
@@ -115,6 +116,28 @@ func checkHeight(recvHeight string) int64 {
 	return height
 }
 
+// Function to check if height is relative to latest height, returning how
+// many blocks below latest height it refers to. Selectors are latest,
+// latest-N and finalized, which is the latest block whose commit is
+// available, i.e. latest-1. Every committed block is final in Tendermint,
+// but the commit of latest block is only included in the next block.
+func checkHeightSelector(recvHeight string) (int64, bool) {
+	switch {
+	case recvHeight == "latest":
+		return 0, true
+	case recvHeight == "finalized":
+		return 1, true
+	case strings.HasPrefix(recvHeight, "latest-"):
+		offset, err := strconv.ParseInt(
+			strings.TrimPrefix(recvHeight, "latest-"), 10, 64)
+		if err != nil || offset < 0 {
+			return 0, false
+		}
+		return offset, true
+	}
+	return 0, false
+}
+
 // Function to check if Kind is valid
 func checkKind(recvKind string) int64 {
 
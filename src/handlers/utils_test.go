@@ -0,0 +1,39 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serveRequest serves GET request for path with query parameters params
+// using handler
+func serveRequest(h http.HandlerFunc, path string,
+	params map[string]string) *httptest.ResponseRecorder {
+
+	req, _ := http.NewRequest("GET", path, nil)
+	q := req.URL.Query()
+	for key, value := range params {
+		q.Add(key, value)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+// checkErrorResponse checks that handler replied with expected error
+func checkErrorResponse(t *testing.T, rr *httptest.ResponseRecorder,
+	expected string) {
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	"github.com/oasisprotocol/oasis-core/go/consensus/tendermint/crypto"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	scheduler "github.com/oasisprotocol/oasis-core/go/scheduler/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+// validatorOverview joins registered node, escrow account and incoming
+// delegations of validator at height
+func validatorOverview(ctx context.Context, co consensus.ClientBackend,
+	units tokenUnits, epoch beacon.EpochTime, height int64,
+	validator *scheduler.Validator) (*responses.ValidatorOverview, error) {
+
+	node, err := co.Registry().GetNode(ctx, &registry.IDQuery{
+		Height: height, ID: validator.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	// Escrow of validator is held by account of its entity
+	address := staking.NewAddress(node.EntityID)
+	account, err := accountAt(ctx, co.Staking(), address, height)
+	if err != nil {
+		return nil, err
+	}
+	delegations, err := co.Staking().DelegationsTo(ctx, &staking.OwnerQuery{
+		Height: height, Owner: address})
+	if err != nil {
+		return nil, err
+	}
+
+	overview := &responses.ValidatorOverview{
+		NodeID:             node.ID,
+		EntityID:           node.EntityID,
+		EntityAddress:      address,
+		ConsensusPublicKey: node.Consensus.ID,
+		TendermintAddress: crypto.PublicKeyToTendermint(
+			&node.Consensus.ID).Address(),
+		VotingPower:  validator.VotingPower,
+		Escrow:       account.Escrow.Active.Balance,
+		EscrowTokens: units.format(&account.Escrow.Active.Balance),
+		Delegators:   len(delegations),
+	}
+	schedule := &account.Escrow.CommissionSchedule
+	if rate := schedule.CurrentRate(epoch); rate != nil {
+		current := percentage(rate, staking.CommissionRateDenominator)
+		overview.CommissionRate = &current
+	}
+	return overview, nil
+}
+
+// validatorOverviews looks up every validator at height using a bounded
+// pool of workers and ranks them by voting power, breaking ties by escrow
+// balance.
+func validatorOverviews(ctx context.Context, co consensus.ClientBackend,
+	height int64) (*responses.ValidatorOverviews, error) {
+
+	// Latest height is resolved so that every lookup is made at the same
+	// height
+	blk, err := co.GetBlock(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	height = blk.Height
+
+	units, err := getTokenUnits(ctx, co.Staking())
+	if err != nil {
+		return nil, err
+	}
+	epoch, err := co.Beacon().GetEpoch(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	validators, err := co.Scheduler().GetValidators(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	overviews := &responses.ValidatorOverviews{
+		TokenSymbol: units.symbol,
+		Epoch:       epoch,
+		Validators: make([]*responses.ValidatorOverview,
+			len(validators)),
+	}
+	err = runWorkers(ctx, len(validators), func(ctx context.Context,
+		i int) error {
+
+		overview, err := validatorOverview(ctx, co, units, epoch, height,
+			validators[i])
+		overviews.Validators[i] = overview
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(overviews.Validators, func(i, j int) bool {
+		a, b := overviews.Validators[i], overviews.Validators[j]
+		if a.VotingPower != b.VotingPower {
+			return a.VotingPower > b.VotingPower
+		}
+		if c := a.Escrow.Cmp(&b.Escrow); c != 0 {
+			return c > 0
+		}
+		return bytes.Compare(a.NodeID[:], b.NodeID[:]) < 0
+	})
+	for _, overview := range overviews.Validators {
+		overviews.TotalVotingPower += overview.VotingPower
+	}
+	for i, overview := range overviews.Validators {
+		overview.Rank = i + 1
+		if overviews.TotalVotingPower > 0 {
+			overview.VotingPowerShare = float64(overview.VotingPower) *
+				100 / float64(overviews.TotalVotingPower)
+		}
+	}
+	return overviews, nil
+}
+
+// GetValidatorOverviews returns every validator at a height with its
+// voting power, node, entity, Tendermint address, escrow balance,
+// commission rate, number of delegators and rank by voting power.
+func GetValidatorOverviews(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving name of node from query request
+	nodeName := r.URL.Query().Get("name")
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Node name requested doesn't exist"})
+		return
+	}
+
+	// Retrieving height from query request
+	recvHeight := r.URL.Query().Get("height")
+	height := checkHeight(recvHeight)
+	if height == -1 {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Unexpected value found, height needs to be a " +
+				"string representing an int!"})
+		return
+	}
+
+	// Attempt to load connection with consensus client
+	connection, co := loadConsensusClient(socket)
+
+	// Close connection once code underneath executes
+	defer connection.Close()
+
+	// If null object was retrieved send response
+	if co == nil {
+
+		// Stop code here faild to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to establish connection using socket: " +
+				socket})
+		return
+	}
+
+	overviews, err := validatorOverviews(context.Background(), co, height)
+	if err != nil {
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Failed to retrieve Validator Overviews!"})
+
+		lgr.Error.Println("Request at /api/scheduler/validatoroverviews "+
+			"failed to retrieve Validator Overviews : ", err)
+		return
+	}
+
+	lgr.Info.Println("Request at /api/scheduler/validatoroverviews " +
+		"responding with Validator Overviews!")
+	json.NewEncoder(w).Encode(responses.ValidatorOverviewsResponse{
+		Height: height, ValidatorOverviews: overviews})
+}
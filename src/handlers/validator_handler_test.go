@@ -0,0 +1,67 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	hdl "github.com/SimplyVC/oasis_api_server/src/handlers"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+)
+
+func Test_GetValidatorOverviews_BadNode(t *testing.T) {
+	rr := serveRequest(hdl.GetValidatorOverviews,
+		"/api/scheduler/validatoroverviews",
+		map[string]string{"name": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Node name requested doesn't exist"}`)
+}
+
+func Test_GetValidatorOverviews_InvalidHeight(t *testing.T) {
+	rr := serveRequest(hdl.GetValidatorOverviews,
+		"/api/scheduler/validatoroverviews",
+		map[string]string{"name": "Oasis_Local", "height": "Unicorn"})
+	checkErrorResponse(t, rr, `{"error":"Unexpected value found, height `+
+		`needs to be a string representing an int!"}`)
+}
+
+func Test_GetValidatorOverviews(t *testing.T) {
+	rr := serveRequest(hdl.GetValidatorOverviews,
+		"/api/scheduler/validatoroverviews",
+		map[string]string{"name": "Oasis_Local"})
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	overviews := &responses.ValidatorOverviewsResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), overviews)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	result := overviews.ValidatorOverviews
+	if result == nil || len(result.Validators) == 0 {
+		t.Fatalf("handler returned unexpected body: got %v",
+			rr.Body.String())
+	}
+
+	// Validators are ranked by voting power, which adds up to total
+	power := int64(0)
+	for i, validator := range result.Validators {
+		if validator.Rank != i+1 {
+			t.Errorf("handler returned wrong rank: got %v want %v",
+				validator.Rank, i+1)
+		}
+		if i > 0 &&
+			validator.VotingPower > result.Validators[i-1].VotingPower {
+			t.Errorf("handler returned unordered validators: got %v "+
+				"after %v", validator.VotingPower,
+				result.Validators[i-1].VotingPower)
+		}
+		power += validator.VotingPower
+	}
+	if power != result.TotalVotingPower {
+		t.Errorf("handler returned wrong total voting power: got %v want %v",
+			result.TotalVotingPower, power)
+	}
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+)
+
+// Settings used for WebSocket connections
+const (
+	// Time allowed to write a message to the client
+	wsWriteWait = 10 * time.Second
+
+	// Time allowed between pongs received from the client
+	wsPongWait = 60 * time.Second
+
+	// Period at which pings are sent, must be less than wsPongWait
+	wsPingPeriod = (wsPongWait * 9) / 10
+
+	// Maximum size of a message the client is allowed to send
+	wsReadLimit = 512
+
+	// Number of messages queued for a client before it's considered too slow
+	wsSendBuffer = 32
+
+	// Maximum number of blocks that are filled in after resubscribing
+	wsMaxBackfill = 100
+)
+
+// wsUpgrader upgrades HTTP connections to WebSocket connections
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+
+	// API is not restricted to any origin
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// WatchBlocks upgrades the connection to a WebSocket and pushes a summary of
+// every consensus block of the node as soon as it is finalized.
+func WatchBlocks(w http.ResponseWriter, r *http.Request) {
+
+	// Add header so that received knows they're receiving JSON
+	w.Header().Add("Content-Type", "application/json")
+
+	// Retrieving name of node from query request
+	nodeName := r.URL.Query().Get("name")
+	confirmation, socket := checkNodeName(nodeName)
+	if !confirmation {
+
+		// Stop code here no need to establish connection and reply
+		json.NewEncoder(w).Encode(responses.ErrorResponse{
+			Error: "Node name requested doesn't exist"})
+		return
+	}
+
+	// Upgrade connection, on failure upgrader replies to client itself
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		lgr.Error.Println("Request at /api/consensus/watchblocks failed "+
+			"to upgrade connection to WebSocket : ", err)
+		return
+	}
+
+	// Close connection once client or subscription is done
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Client isn't expected to send anything but reading is required for
+	// pongs and close messages to be processed.
+	conn.SetReadLimit(wsReadLimit)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Subscribe to blocks of node, resubscribing if stream drops
+	blocks := make(chan *responses.NewBlock, wsSendBuffer)
+	done := make(chan error, 1)
+	go func() {
+		var lastHeight int64
+		done <- keepSubscribed(ctx, "blocks of "+nodeName,
+			func(ctx context.Context, reset func()) error {
+				return subscribeBlocks(ctx, socket, &lastHeight, blocks,
+					reset)
+			})
+	}()
+
+	lgr.Info.Println("Request at /api/consensus/watchblocks subscribed to " +
+		"blocks of " + nodeName)
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	for {
+		select {
+		case blk := <-blocks:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err := conn.WriteJSON(responses.NewBlockResponse{NewBlock: blk})
+			if err != nil {
+				lgr.Warning.Println("Request at /api/consensus/watchblocks "+
+					"failed to send Block : ", err)
+				return
+			}
+		case <-ping.C:
+			err := conn.WriteControl(websocket.PingMessage, nil,
+				time.Now().Add(wsWriteWait))
+			if err != nil {
+				lgr.Warning.Println("Request at /api/consensus/watchblocks "+
+					"failed to ping client : ", err)
+				return
+			}
+		case err := <-done:
+
+			// Let client know why it was dropped if it was too slow
+			if errors.Is(err, errSubscriberTooSlow) {
+				lgr.Warning.Println("Request at /api/consensus/watchblocks " +
+					"dropped client that is not keeping up with blocks!")
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(
+						websocket.ClosePolicyViolation,
+						"Client is not keeping up with blocks"),
+					time.Now().Add(wsWriteWait))
+			}
+			lgr.Info.Println("Request at /api/consensus/watchblocks " +
+				"unsubscribed from blocks of " + nodeName)
+			return
+		}
+	}
+}
+
+// subscribeBlocks watches blocks of node at socket and sends a summary of
+// each one to blocks until the stream ends. Blocks that were finalized after
+// lastHeight while resubscribing are filled in first.
+func subscribeBlocks(ctx context.Context, socket string, lastHeight *int64,
+	blocks chan<- *responses.NewBlock, reset func()) error {
+
+	// Attempt to load connection with consensus client
+	connection, co := loadConsensusClient(socket)
+	if co == nil {
+		return fmt.Errorf("failed to establish connection using socket: %s",
+			socket)
+	}
+
+	// Close connection once subscription ends
+	defer connection.Close()
+
+	blkCh, sub, err := co.WatchBlocks(ctx)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	// Summarises block and queues it, without blocking on slow clients
+	send := func(blk *consensus.Block) error {
+		txs, err := co.GetTransactions(ctx, blk.Height)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case blocks <- &responses.NewBlock{
+			Height:          blk.Height,
+			Hash:            blk.Hash,
+			Time:            blk.Time,
+			NumTransactions: len(txs),
+		}:
+		default:
+			return errSubscriberTooSlow
+		}
+		*lastHeight = blk.Height
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case blk, ok := <-blkCh:
+			if !ok {
+				return errors.New("block stream closed by node")
+			}
+			reset()
+
+			// Skip blocks that were already sent before resubscribing
+			if blk.Height <= *lastHeight {
+				continue
+			}
+
+			// Fill in blocks missed while subscription was down
+			if *lastHeight > 0 {
+				from := *lastHeight + 1
+				if blk.Height-from > wsMaxBackfill {
+					from = blk.Height - wsMaxBackfill
+				}
+				for height := from; height < blk.Height; height++ {
+					missed, err := co.GetBlock(ctx, height)
+					if err != nil {
+						return err
+					}
+					if err := send(missed); err != nil {
+						return err
+					}
+				}
+			}
+
+			if err := send(blk); err != nil {
+				return err
+			}
+		}
+	}
+}
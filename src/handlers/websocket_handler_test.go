@@ -0,0 +1,75 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	hdl "github.com/SimplyVC/oasis_api_server/src/handlers"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+)
+
+func Test_WatchBlocks_BadNode(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/consensus/watchblocks", nil)
+	q := req.URL.Query()
+	q.Add("name", "Unicorn")
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.WatchBlocks)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	expected := `{"error":"Node name requested doesn't exist"}`
+
+	if strings.TrimSpace(rr.Body.String()) != strings.TrimSpace(expected) {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			rr.Body.String(), expected)
+	}
+}
+
+func Test_WatchBlocks_NotUpgraded(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/consensus/watchblocks", nil)
+	q := req.URL.Query()
+	q.Add("name", "Oasis_Local")
+	req.URL.RawQuery = q.Encode()
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(hdl.WatchBlocks)
+	handler.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusBadRequest)
+	}
+}
+
+func Test_WatchBlocks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(hdl.WatchBlocks))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") +
+		"/api/consensus/watchblocks?name=Oasis_Local"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to WebSocket : %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+	newBlock := &responses.NewBlockResponse{}
+	if err := conn.ReadJSON(newBlock); err != nil {
+		t.Fatalf("Failed to read Block from WebSocket : %v", err)
+	}
+
+	if newBlock.NewBlock == nil || newBlock.NewBlock.Height <= 0 {
+		t.Errorf("handler returned unexpected block: got %v",
+			newBlock.NewBlock)
+	}
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+)
+
+// Number of lookups run concurrently by a pool of workers
+const poolWorkers = 8
+
+// runWorkers calls work for every index below n using a bounded pool of
+// workers, stopping at the first error which is returned.
+func runWorkers(ctx context.Context, n int,
+	work func(ctx context.Context, i int) error) error {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for w := 0; w < poolWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := work(ctx, i); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return firstErr
+}
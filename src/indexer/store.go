@@ -0,0 +1,435 @@
+// Package indexer implements a persistent index of blocks, transactions and
+// events of a node, stored in an embedded key-value store so that history
+// remains available once the node prunes its state.
+package indexer
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/dgraph-io/badger/v2"
+
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+// ErrNotIndexed is returned when data requested has not been indexed
+var ErrNotIndexed = errors.New("indexer: not indexed")
+
+// Prefixes of keys of each kind of record
+const (
+	prefixMeta        = 'm'
+	prefixBlock       = 'b'
+	prefixTransaction = 't'
+	prefixStaking     = 's'
+	prefixRegistry    = 'r'
+	prefixAccount     = 'a'
+)
+
+// Keys of metadata records
+var (
+	keyFirstHeight = []byte{prefixMeta, 'f'}
+	keyCheckpoint  = []byte{prefixMeta, 'c'}
+	keyGaps        = []byte{prefixMeta, 'g'}
+)
+
+// Kinds of account items
+const (
+	// ItemTransaction is a transaction involving an account
+	ItemTransaction = "transaction"
+
+	// ItemEvent is a staking event involving an account
+	ItemEvent = "event"
+)
+
+// AccountItem refers to a transaction or staking event of a block in which
+// an account takes part
+type AccountItem struct {
+	Address staking.Address `json:"-"`
+	Height  int64           `json:"height"`
+	Kind    string          `json:"kind"`
+	Index   int             `json:"index"`
+}
+
+// Gap is a range of heights skipped as node no longer retained them
+type Gap struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// Status holds range of heights indexed and gaps within it
+type Status struct {
+	FirstHeight int64 `json:"first_height"`
+	Checkpoint  int64 `json:"checkpoint"`
+	Gaps        []Gap `json:"gaps,omitempty"`
+}
+
+// Store is an index persisted in a badger database
+type Store struct {
+	db *badger.DB
+
+	// Serialises writes so that blocks are added in order
+	mutex sync.Mutex
+}
+
+// badgerLogger forwards badger logs to logger of API
+type badgerLogger struct{}
+
+func (badgerLogger) Errorf(f string, v ...interface{}) {
+	lgr.Error.Printf("Indexer: "+f, v...)
+}
+
+func (badgerLogger) Warningf(f string, v ...interface{}) {
+	lgr.Warning.Printf("Indexer: "+f, v...)
+}
+
+func (badgerLogger) Infof(f string, v ...interface{}) {}
+
+func (badgerLogger) Debugf(f string, v ...interface{}) {}
+
+// Open opens index stored in directory at path, creating it if needed
+func Open(path string) (*Store, error) {
+	db, err := badger.Open(badger.DefaultOptions(path).
+		WithLogger(badgerLogger{}))
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes index
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// heightKey returns key of record of given kind at height
+func heightKey(prefix byte, height int64) []byte {
+	key := make([]byte, 9)
+	key[0] = prefix
+	binary.BigEndian.PutUint64(key[1:], uint64(height))
+	return key
+}
+
+// itemKey returns key of record of given kind at height and index
+func itemKey(prefix byte, height int64, index int) []byte {
+	key := make([]byte, 13)
+	copy(key, heightKey(prefix, height))
+	binary.BigEndian.PutUint32(key[9:], uint32(index))
+	return key
+}
+
+// accountPrefix returns prefix of keys of items involving address
+func accountPrefix(address staking.Address) []byte {
+	raw, _ := address.MarshalBinary()
+	return append([]byte{prefixAccount}, raw...)
+}
+
+// accountKey returns key of account item, ordered by height, then by kind
+// and then by index
+func accountKey(item *AccountItem) []byte {
+	key := accountPrefix(item.Address)
+	key = append(key, heightKey(0, item.Height)[1:]...)
+	key = append(key, item.Kind[0])
+	index := make([]byte, 4)
+	binary.BigEndian.PutUint32(index, uint32(item.Index))
+	return append(key, index...)
+}
+
+// parseAccountKey parses key of account item
+func parseAccountKey(address staking.Address, key []byte) AccountItem {
+	rest := key[len(accountPrefix(address)):]
+	item := AccountItem{
+		Address: address,
+		Height:  int64(binary.BigEndian.Uint64(rest[:8])),
+		Index:   int(binary.BigEndian.Uint32(rest[9:13])),
+	}
+	if rest[8] == ItemTransaction[0] {
+		item.Kind = ItemTransaction
+	} else {
+		item.Kind = ItemEvent
+	}
+	return item
+}
+
+// getInt64 reads number stored under key, returning 0 if there is none
+func getInt64(txn *badger.Txn, key []byte) (int64, error) {
+	item, err := txn.Get(key)
+	if err == badger.ErrKeyNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	value, err := item.ValueCopy(nil)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(value)), nil
+}
+
+// getJSON decodes JSON record stored under key into v
+func getJSON(txn *badger.Txn, key []byte, v interface{}) error {
+	item, err := txn.Get(key)
+	if err == badger.ErrKeyNotFound {
+		return ErrNotIndexed
+	}
+	if err != nil {
+		return err
+	}
+	return item.Value(func(value []byte) error {
+		return json.Unmarshal(value, v)
+	})
+}
+
+// setJSON stores v encoded as JSON under key
+func setJSON(txn *badger.Txn, key []byte, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return txn.Set(key, value)
+}
+
+// getGaps reads gaps skipped so far, returning none if there are none
+func getGaps(txn *badger.Txn) ([]Gap, error) {
+	gaps := []Gap{}
+	if err := getJSON(txn, keyGaps, &gaps); err != nil &&
+		err != ErrNotIndexed {
+		return nil, err
+	}
+	return gaps, nil
+}
+
+// Status returns range of heights indexed, which is empty until a block is
+// added, and gaps skipped within it
+func (s *Store) Status() (Status, error) {
+	var status Status
+	err := s.db.View(func(txn *badger.Txn) error {
+		var err error
+		status.FirstHeight, err = getInt64(txn, keyFirstHeight)
+		if err != nil {
+			return err
+		}
+		status.Checkpoint, err = getInt64(txn, keyCheckpoint)
+		if err != nil {
+			return err
+		}
+		status.Gaps, err = getGaps(txn)
+		return err
+	})
+	return status, err
+}
+
+// Skip moves checkpoint of index which isn't empty past heights following
+// it up to height, recording them as a gap. It allows indexing to resume
+// once node has pruned blocks which weren't indexed yet.
+func (s *Store) Skip(height int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.db.Update(func(txn *badger.Txn) error {
+		checkpoint, err := getInt64(txn, keyCheckpoint)
+		if err != nil {
+			return err
+		}
+		if checkpoint == 0 || height <= checkpoint {
+			return errors.New("indexer: height doesn't follow checkpoint")
+		}
+
+		gaps, err := getGaps(txn)
+		if err != nil {
+			return err
+		}
+		gaps = append(gaps, Gap{From: checkpoint + 1, To: height})
+		if err := setJSON(txn, keyGaps, gaps); err != nil {
+			return err
+		}
+		return txn.Set(keyCheckpoint, heightKey(0, height)[1:])
+	})
+}
+
+// Put adds block at height together with items of accounts taking part in
+// it. Height must directly follow checkpoint unless index is empty, and
+// checkpoint is moved to it.
+func (s *Store) Put(height int64, block *responses.IndexedBlock,
+	accounts []AccountItem) error {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.db.Update(func(txn *badger.Txn) error {
+		checkpoint, err := getInt64(txn, keyCheckpoint)
+		if err != nil {
+			return err
+		}
+		if checkpoint != 0 && height != checkpoint+1 {
+			return errors.New("indexer: height doesn't follow checkpoint")
+		}
+
+		if err := setJSON(txn, heightKey(prefixBlock, height),
+			block.Block); err != nil {
+			return err
+		}
+		for i, tx := range block.Transactions {
+			key := itemKey(prefixTransaction, height, i)
+			if err := setJSON(txn, key, tx); err != nil {
+				return err
+			}
+		}
+		for i, ev := range block.StakingEvents {
+			key := itemKey(prefixStaking, height, i)
+			if err := setJSON(txn, key, ev); err != nil {
+				return err
+			}
+		}
+		for i, ev := range block.RegistryEvents {
+			key := itemKey(prefixRegistry, height, i)
+			if err := setJSON(txn, key, ev); err != nil {
+				return err
+			}
+		}
+		for i := range accounts {
+			if err := txn.Set(accountKey(&accounts[i]), nil); err != nil {
+				return err
+			}
+		}
+
+		value := heightKey(0, height)[1:]
+		if checkpoint == 0 {
+			if err := txn.Set(keyFirstHeight, value); err != nil {
+				return err
+			}
+		}
+		return txn.Set(keyCheckpoint, value)
+	})
+}
+
+// Get returns block indexed at height
+func (s *Store) Get(height int64) (*responses.IndexedBlock, error) {
+	block := &responses.IndexedBlock{
+		Transactions:   []*responses.TransactionWithResult{},
+		StakingEvents:  []*staking.Event{},
+		RegistryEvents: []*registry.Event{},
+	}
+	err := s.db.View(func(txn *badger.Txn) error {
+		if err := getJSON(txn, heightKey(prefixBlock, height),
+			&block.Block); err != nil {
+			return err
+		}
+
+		opts := badger.DefaultIteratorOptions
+		for _, prefix := range []byte{prefixTransaction, prefixStaking,
+			prefixRegistry} {
+
+			opts.Prefix = heightKey(prefix, height)
+			it := txn.NewIterator(opts)
+			for it.Rewind(); it.Valid(); it.Next() {
+				err := it.Item().Value(func(value []byte) error {
+					switch prefix {
+					case prefixTransaction:
+						tx := &responses.TransactionWithResult{}
+						block.Transactions = append(block.Transactions, tx)
+						return json.Unmarshal(value, tx)
+					case prefixStaking:
+						ev := &staking.Event{}
+						block.StakingEvents = append(block.StakingEvents, ev)
+						return json.Unmarshal(value, ev)
+					default:
+						ev := &registry.Event{}
+						block.RegistryEvents = append(block.RegistryEvents,
+							ev)
+						return json.Unmarshal(value, ev)
+					}
+				})
+				if err != nil {
+					it.Close()
+					return err
+				}
+			}
+			it.Close()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// Transaction returns transaction indexed at height and index
+func (s *Store) Transaction(height int64, index int) (
+	*responses.TransactionWithResult, error) {
+
+	tx := &responses.TransactionWithResult{}
+	err := s.db.View(func(txn *badger.Txn) error {
+		return getJSON(txn, itemKey(prefixTransaction, height, index), tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// StakingEvent returns staking event indexed at height and index
+func (s *Store) StakingEvent(height int64, index int) (*staking.Event,
+	error) {
+
+	ev := &staking.Event{}
+	err := s.db.View(func(txn *badger.Txn) error {
+		return getJSON(txn, itemKey(prefixStaking, height, index), ev)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
+
+// AccountItems returns at most limit items involving address from heights
+// in range, ordered by height. Items up to and including after, if given,
+// are skipped so that results can be paginated.
+func (s *Store) AccountItems(address staking.Address, fromHeight int64,
+	toHeight int64, after *AccountItem, limit int) ([]AccountItem, error) {
+
+	items := []AccountItem{}
+	prefix := accountPrefix(address)
+	start := accountKey(&AccountItem{Address: address, Height: fromHeight,
+		Kind: ItemEvent})
+	var skip []byte
+	if after != nil {
+		after.Address = address
+		skip = accountKey(after)
+	}
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		seek := start
+		if skip != nil && string(skip) > string(seek) {
+			seek = skip
+		}
+		for it.Seek(seek); it.Valid() && len(items) < limit; it.Next() {
+			key := it.Item().Key()
+			if skip != nil && string(key) <= string(skip) {
+				continue
+			}
+			item := parseAccountKey(address, key)
+			if toHeight > 0 && item.Height > toHeight {
+				break
+			}
+			items = append(items, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,190 @@
+package indexer_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/SimplyVC/oasis_api_server/src/indexer"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+var (
+	unicorn = staking.NewAddress(signature.NewPublicKey(
+		"0000000000000000000000000000000000000000000000000000000000000001"))
+	pegasus = staking.NewAddress(signature.NewPublicKey(
+		"0000000000000000000000000000000000000000000000000000000000000002"))
+)
+
+func openStore(t *testing.T) (*indexer.Store, func()) {
+	dir, err := ioutil.TempDir("", "indexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := indexer.Open(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return store, func() {
+		store.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// transferBlock returns block at height holding a transfer from unicorn to
+// pegasus, together with items of accounts taking part in it
+func transferBlock(height int64) (*responses.IndexedBlock,
+	[]indexer.AccountItem) {
+
+	return &responses.IndexedBlock{
+		Block: &consensus.Block{Height: height},
+		Transactions: []*responses.TransactionWithResult{{
+			Transaction: &responses.DecodedTransaction{
+				SignerAddress: unicorn,
+				Method:        staking.MethodTransfer,
+				Nonce:         uint64(height),
+			},
+			Success: true,
+		}},
+		StakingEvents: []*staking.Event{{
+			Height: height,
+			Transfer: &staking.TransferEvent{From: unicorn, To: pegasus,
+				Amount: *quantity.NewFromUint64(uint64(height))},
+		}},
+	}, []indexer.AccountItem{
+		{Address: unicorn, Height: height, Kind: indexer.ItemTransaction},
+		{Address: unicorn, Height: height, Kind: indexer.ItemEvent},
+		{Address: pegasus, Height: height, Kind: indexer.ItemEvent},
+	}
+}
+
+// putBlock adds transfer block at height to store
+func putBlock(store *indexer.Store, height int64) error {
+	block, accounts := transferBlock(height)
+	return store.Put(height, block, accounts)
+}
+
+func Test_Store_Put(t *testing.T) {
+	store, done := openStore(t)
+	defer done()
+
+	if status, _ := store.Status(); status.Checkpoint != 0 {
+		t.Errorf("empty store has checkpoint %v", status.Checkpoint)
+	}
+
+	for height := int64(10); height <= 12; height++ {
+		if err := putBlock(store, height); err != nil {
+			t.Fatalf("failed to put block %v: %v", height, err)
+		}
+	}
+
+	status, err := store.Status()
+	if err != nil || status.FirstHeight != 10 || status.Checkpoint != 12 {
+		t.Errorf("store returned unexpected status: got %+v", status)
+	}
+
+	// Blocks must be added in order
+	if err := putBlock(store, 14); err == nil {
+		t.Errorf("store accepted block not following checkpoint")
+	}
+}
+
+func Test_Store_Skip(t *testing.T) {
+	store, done := openStore(t)
+	defer done()
+
+	// Nothing can be skipped before first block is added
+	if err := store.Skip(5); err == nil {
+		t.Errorf("empty store skipped heights")
+	}
+
+	putBlock(store, 10)
+	if err := store.Skip(14); err != nil {
+		t.Fatalf("failed to skip heights: %v", err)
+	}
+	if err := putBlock(store, 15); err != nil {
+		t.Fatalf("failed to put block following gap: %v", err)
+	}
+
+	status, err := store.Status()
+	if err != nil || status.FirstHeight != 10 || status.Checkpoint != 15 ||
+		len(status.Gaps) != 1 || status.Gaps[0].From != 11 ||
+		status.Gaps[0].To != 14 {
+		t.Errorf("store returned unexpected status: got %+v", status)
+	}
+	if _, err := store.Get(12); err != indexer.ErrNotIndexed {
+		t.Errorf("store returned unexpected error: got %v want %v", err,
+			indexer.ErrNotIndexed)
+	}
+	if err := store.Skip(15); err == nil {
+		t.Errorf("store skipped height not following checkpoint")
+	}
+}
+
+func Test_Store_Get(t *testing.T) {
+	store, done := openStore(t)
+	defer done()
+
+	putBlock(store, 10)
+
+	block, err := store.Get(10)
+	if err != nil {
+		t.Fatalf("failed to get block: %v", err)
+	}
+	if block.Block.Height != 10 || len(block.Transactions) != 1 ||
+		len(block.StakingEvents) != 1 || len(block.RegistryEvents) != 0 {
+		t.Errorf("store returned unexpected block: got %+v", block)
+	}
+	if method := block.Transactions[0].Transaction.Method; method !=
+		transaction.MethodName(staking.MethodTransfer) {
+		t.Errorf("store returned unexpected transaction method: got %v",
+			method)
+	}
+
+	if _, err := store.Get(11); err != indexer.ErrNotIndexed {
+		t.Errorf("store returned unexpected error: got %v want %v", err,
+			indexer.ErrNotIndexed)
+	}
+}
+
+func Test_Store_AccountItems(t *testing.T) {
+	store, done := openStore(t)
+	defer done()
+
+	for height := int64(10); height <= 14; height++ {
+		putBlock(store, height)
+	}
+
+	items, err := store.AccountItems(unicorn, 11, 13, nil, 100)
+	if err != nil || len(items) != 6 {
+		t.Fatalf("store returned unexpected items: got %+v", items)
+	}
+	if items[0].Height != 11 || items[0].Kind != indexer.ItemEvent ||
+		items[5].Height != 13 || items[5].Kind != indexer.ItemTransaction {
+		t.Errorf("store returned items out of order: got %+v", items)
+	}
+
+	// Pages continue after last item returned
+	page, _ := store.AccountItems(unicorn, 11, 13, nil, 4)
+	rest, _ := store.AccountItems(unicorn, 11, 13, &page[3], 4)
+	if len(page) != 4 || len(rest) != 2 || rest[0] != items[4] {
+		t.Errorf("store returned unexpected pages: got %+v and %+v", page,
+			rest)
+	}
+
+	items, _ = store.AccountItems(pegasus, 0, 0, nil, 100)
+	if len(items) != 5 {
+		t.Errorf("store returned unexpected items: got %+v", items)
+	}
+
+	ev, err := store.StakingEvent(items[0].Height, items[0].Index)
+	if err != nil || ev.Transfer == nil || !ev.Transfer.To.Equal(pegasus) {
+		t.Errorf("store returned unexpected event: got %+v", ev)
+	}
+}
@@ -0,0 +1,354 @@
+// Package jsonstream encodes large values as JSON incrementally. Structs,
+// maps and slices are written element by element so that the whole document
+// never has to be held in memory, producing the same output as
+// encoding/json.
+package jsonstream
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Size of buffered output after which it is flushed to underlying writer
+const flushSize = 32 * 1024
+
+var (
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// flusher is implemented by writers such as gzip.Writer
+type flusher interface {
+	Flush() error
+}
+
+// httpFlusher is implemented by writers such as http.ResponseWriter
+type httpFlusher interface {
+	Flush()
+}
+
+// Encoder writes JSON values to an output stream
+type Encoder struct {
+	out      io.Writer
+	buf      *bufio.Writer
+	maxDepth int
+}
+
+// NewEncoder returns an encoder writing to w which walks values up to
+// maxDepth levels deep, values nested deeper are encoded in one go.
+func NewEncoder(w io.Writer, maxDepth int) *Encoder {
+	return &Encoder{
+		out:      w,
+		buf:      bufio.NewWriterSize(w, flushSize),
+		maxDepth: maxDepth,
+	}
+}
+
+// Encode writes JSON encoding of v followed by a newline, like
+// json.Encoder does, and flushes all output.
+func (e *Encoder) Encode(v interface{}) error {
+	if err := e.encode(reflect.ValueOf(v), 0); err != nil {
+		return err
+	}
+	if err := e.buf.WriteByte('\n'); err != nil {
+		return err
+	}
+	return e.flush()
+}
+
+// flush writes buffered output and flushes underlying writer if it
+// supports it
+func (e *Encoder) flush() error {
+	if err := e.buf.Flush(); err != nil {
+		return err
+	}
+	switch f := e.out.(type) {
+	case flusher:
+		return f.Flush()
+	case httpFlusher:
+		f.Flush()
+	}
+	return nil
+}
+
+// maybeFlush flushes output once enough of it is buffered
+func (e *Encoder) maybeFlush() error {
+	if e.buf.Buffered() < flushSize/2 {
+		return nil
+	}
+	return e.flush()
+}
+
+// marshal encodes v in one go using encoding/json
+func (e *Encoder) marshal(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = e.buf.Write(data)
+	return err
+}
+
+// encode writes v, walking into it while depth allows
+func (e *Encoder) encode(v reflect.Value, depth int) error {
+	if !v.IsValid() {
+		_, err := e.buf.WriteString("null")
+		return err
+	}
+
+	// Values deeper than allowed and values with custom encodings are
+	// left to encoding/json
+	if depth >= e.maxDepth {
+		return e.marshal(v.Interface())
+	}
+	if v.Type().Implements(marshalerType) ||
+		v.Type().Implements(textMarshalerType) {
+		return e.marshal(v.Interface())
+	}
+	if v.Kind() != reflect.Ptr && v.CanAddr() {
+		ptrType := reflect.PtrTo(v.Type())
+		if ptrType.Implements(marshalerType) ||
+			ptrType.Implements(textMarshalerType) {
+			return e.marshal(v.Addr().Interface())
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			_, err := e.buf.WriteString("null")
+			return err
+		}
+		return e.encode(v.Elem(), depth)
+	case reflect.Struct:
+		return e.encodeStruct(v, depth)
+	case reflect.Map:
+		return e.encodeMap(v, depth)
+	case reflect.Slice:
+		if v.IsNil() {
+			_, err := e.buf.WriteString("null")
+			return err
+		}
+
+		// Byte slices are encoded as base64 strings
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return e.marshal(v.Interface())
+		}
+		return e.encodeArray(v, depth)
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return e.marshal(v.Interface())
+		}
+		return e.encodeArray(v, depth)
+	default:
+		return e.marshal(v.Interface())
+	}
+}
+
+// field describes how a struct field is encoded
+type field struct {
+	index     int
+	name      string
+	omitEmpty bool
+}
+
+// structFields returns fields of struct type t in encoding order, ok is
+// false if type uses features that are left to encoding/json.
+func structFields(t reflect.Type) (fields []field, ok bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		// Embedded fields are promoted by encoding/json
+		if sf.Anonymous {
+			return nil, false
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx != -1 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		f := field{index: i, name: name}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				f.omitEmpty = true
+			case "string":
+				return nil, false
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields, true
+}
+
+// encodeStruct writes struct field by field
+func (e *Encoder) encodeStruct(v reflect.Value, depth int) error {
+	fields, ok := structFields(v.Type())
+	if !ok {
+		return e.marshal(v.Interface())
+	}
+
+	if err := e.buf.WriteByte('{'); err != nil {
+		return err
+	}
+	first := true
+	for _, f := range fields {
+		fv := v.Field(f.index)
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		if !first {
+			if err := e.buf.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		if err := e.marshal(f.name); err != nil {
+			return err
+		}
+		if err := e.buf.WriteByte(':'); err != nil {
+			return err
+		}
+		if err := e.encode(fv, depth+1); err != nil {
+			return err
+		}
+		if err := e.maybeFlush(); err != nil {
+			return err
+		}
+	}
+	return e.buf.WriteByte('}')
+}
+
+// mapKey returns string used for map key in JSON
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return "", nil
+		}
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("jsonstream: unsupported map key type %s",
+		k.Type())
+}
+
+// encodeMap writes map entry by entry, sorted by key like encoding/json
+func (e *Encoder) encodeMap(v reflect.Value, depth int) error {
+	if v.IsNil() {
+		_, err := e.buf.WriteString("null")
+		return err
+	}
+
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{key: key, value: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	if err := e.buf.WriteByte('{'); err != nil {
+		return err
+	}
+	for i, en := range entries {
+		if i > 0 {
+			if err := e.buf.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		if err := e.marshal(en.key); err != nil {
+			return err
+		}
+		if err := e.buf.WriteByte(':'); err != nil {
+			return err
+		}
+		if err := e.encode(en.value, depth+1); err != nil {
+			return err
+		}
+		if err := e.maybeFlush(); err != nil {
+			return err
+		}
+	}
+	return e.buf.WriteByte('}')
+}
+
+// encodeArray writes slice or array element by element
+func (e *Encoder) encodeArray(v reflect.Value, depth int) error {
+	if err := e.buf.WriteByte('['); err != nil {
+		return err
+	}
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			if err := e.buf.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		if err := e.encode(v.Index(i), depth+1); err != nil {
+			return err
+		}
+		if err := e.maybeFlush(); err != nil {
+			return err
+		}
+	}
+	return e.buf.WriteByte(']')
+}
+
+// isEmptyValue reports whether v is empty as defined by omitempty
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package jsonstream_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SimplyVC/oasis_api_server/src/jsonstream"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+)
+
+type textKey struct {
+	a, b string
+}
+
+func (k textKey) MarshalText() ([]byte, error) {
+	return []byte(k.a + "/" + k.b), nil
+}
+
+type inner struct {
+	Name    string            `json:"name"`
+	Amount  quantity.Quantity `json:"amount"`
+	Skipped string            `json:"-"`
+	Empty   []int             `json:"empty,omitempty"`
+	private int
+}
+
+type outer struct {
+	Height   int64              `json:"height"`
+	Time     time.Time          `json:"time"`
+	Data     []byte             `json:"data"`
+	Inners   []inner            `json:"inners"`
+	ByKey    map[textKey]*inner `json:"by_key"`
+	ByInt    map[int]string     `json:"by_int"`
+	Nil      *inner             `json:"nil"`
+	NilMap   map[string]int     `json:"nil_map"`
+	NilSlice []string           `json:"nil_slice"`
+	Omitted  *inner             `json:"omitted,omitempty"`
+	Any      interface{}        `json:"any"`
+	NoTag    bool
+}
+
+func newOuter() *outer {
+	q := quantity.NewFromUint64(1000)
+	return &outer{
+		Height: 42,
+		Time:   time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC),
+		Data:   []byte("Unicorn"),
+		Inners: []inner{
+			{Name: "a", Amount: *q, Skipped: "x", private: 1},
+			{Name: "b", Empty: []int{1, 2}},
+		},
+		ByKey: map[textKey]*inner{
+			{"z", "1"}: {Name: "z"},
+			{"a", "2"}: {Name: "a", Amount: *q},
+		},
+		ByInt: map[int]string{10: "ten", 2: "two", -1: "minus one"},
+		Any:   map[string]interface{}{"b": []int{1}, "a": nil},
+	}
+}
+
+func Test_Encode(t *testing.T) {
+	for _, depth := range []int{0, 1, 2, 5} {
+		v := newOuter()
+		expected, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("Failed to marshal value : %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := jsonstream.NewEncoder(&buf, depth).Encode(v); err != nil {
+			t.Fatalf("Failed to encode value : %v", err)
+		}
+
+		if buf.String() != string(expected)+"\n" {
+			t.Errorf("encoder with depth %d returned unexpected output: "+
+				"got %v want %v", depth, buf.String(), string(expected))
+		}
+	}
+}
+
+func Test_Encode_Nil(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jsonstream.NewEncoder(&buf, 5).Encode(nil); err != nil {
+		t.Fatalf("Failed to encode value : %v", err)
+	}
+
+	expected := "null"
+
+	if strings.TrimSpace(buf.String()) != expected {
+		t.Errorf("encoder returned unexpected output: got %v want %v",
+			buf.String(), expected)
+	}
+}
+
+func Test_Encode_Large(t *testing.T) {
+	v := make(map[string][]string)
+	for i := 0; i < 1000; i++ {
+		v[strings.Repeat("k", i%50)+string(rune('a'+i%26))] = []string{
+			strings.Repeat("v", 100), "Unicorn"}
+	}
+	expected, _ := json.Marshal(v)
+
+	var buf bytes.Buffer
+	if err := jsonstream.NewEncoder(&buf, 5).Encode(v); err != nil {
+		t.Fatalf("Failed to encode value : %v", err)
+	}
+
+	if buf.String() != string(expected)+"\n" {
+		t.Errorf("encoder returned unexpected output of length %d, want "+
+			"length %d", buf.Len(), len(expected)+1)
+	}
+}
@@ -1,14 +1,21 @@
 package responses
 
 import (
+	"time"
+
+	"github.com/SimplyVC/oasis_api_server/src/cache"
 	"github.com/mackerelio/go-osstat/cpu"
 	"github.com/mackerelio/go-osstat/disk"
 	"github.com/mackerelio/go-osstat/memory"
 	"github.com/mackerelio/go-osstat/network"
 	common_entity "github.com/oasisprotocol/oasis-core/go/common/entity"
+	common_hash "github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	common_signature "github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	common_node "github.com/oasisprotocol/oasis-core/go/common/node"
 	common_quantity "github.com/oasisprotocol/oasis-core/go/common/quantity"
 	consensus_api "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	transaction_api "github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+	results_api "github.com/oasisprotocol/oasis-core/go/consensus/api/transaction/results"
 	beacon_api "github.com/oasisprotocol/oasis-core/go/beacon/api"
 	gen_api "github.com/oasisprotocol/oasis-core/go/genesis/api"
 	registry_api "github.com/oasisprotocol/oasis-core/go/registry/api"
@@ -22,9 +29,15 @@ import (
 
 // StakingEvents responds with a list of events
 type StakingEvents struct {
+	Height        int64                `json:"height,omitempty"`
 	StakingEvents []*staking_api.Event `json:"result"`
 }
 
+// StakingEventResponse responds with a single staking event
+type StakingEventResponse struct {
+	StakingEvent *staking_api.Event `json:"result"`
+}
+
 // TendermintAddress responds with a tendermint public key address
 type TendermintAddress struct {
 	TendermintAddress *tmed.Address `json:"result"`
@@ -57,16 +70,19 @@ type MemoryResponse struct {
 
 // SchedulerGenesisState responds with scheduler genesis state
 type SchedulerGenesisState struct {
+	Height                int64                  `json:"height,omitempty"`
 	SchedulerGenesisState *scheduler_api.Genesis `json:"result"`
 }
 
 // CommitteesResponse responds with Committees
 type CommitteesResponse struct {
+	Height    int64                      `json:"height,omitempty"`
 	Committee []*scheduler_api.Committee `json:"result"`
 }
 
 // ValidatorsResponse responds with Validators and their voting power
 type ValidatorsResponse struct {
+	Height     int64                      `json:"height,omitempty"`
 	Validators []*scheduler_api.Validator `json:"result"`
 }
 
@@ -79,21 +95,25 @@ type IsSyncedResponse struct {
 // DebondingDelegationsResponse responds with debonding delegations
 // for specified public key
 type DebondingDelegationsResponse struct {
+	Height               int64                                                      `json:"height,omitempty"`
 	DebondingDelegations map[staking_api.Address][]*staking_api.DebondingDelegation `json:"result"`
 }
 
 // DelegationsResponse responds with delegations for public key
 type DelegationsResponse struct {
+	Height      int64                                           `json:"height,omitempty"`
 	Delegations map[staking_api.Address]*staking_api.Delegation `json:"result"`
 }
 
 // AccountResponse responds with an account
 type AccountResponse struct {
+	Height  int64                `json:"height,omitempty"`
 	Account *staking_api.Account `json:"result"`
 }
 
 // AllAddressesResponse responds with list of Accounts
 type AllAddressesResponse struct {
+	Height       int64                 `json:"height,omitempty"`
 	AllAddresses []staking_api.Address `json:"result"`
 }
 
@@ -104,36 +124,48 @@ type AddressResponse struct {
 
 // StakingGenesisResponse responds with Staking Genesis File
 type StakingGenesisResponse struct {
+	Height         int64                `json:"height,omitempty"`
 	GenesisStaking *staking_api.Genesis `json:"result"`
 }
 
 // QuantityResponse responds with quantity
 type QuantityResponse struct {
+	Height   int64                     `json:"height,omitempty"`
 	Quantity *common_quantity.Quantity `json:"result"`
 }
 
 // RegistryEntityResponse responds with details of single Entity
 type RegistryEntityResponse struct {
+	Height int64                 `json:"height,omitempty"`
 	Entity *common_entity.Entity `json:"result"`
 }
 
 // RegistryNodeResponse responds with details of single Node
 type RegistryNodeResponse struct {
-	Node *common_node.Node `json:"result"`
+	Height int64             `json:"height,omitempty"`
+	Node   *common_node.Node `json:"result"`
 }
 
 // RegistryEventsResponse responds with events at specified block height.
 type RegistryEventsResponse struct {
+	Height int64                 `json:"height,omitempty"`
 	Events []*registry_api.Event `json:"results"`
 }
 
+// RegistryEventResponse responds with a single registry event
+type RegistryEventResponse struct {
+	Event *registry_api.Event `json:"result"`
+}
+
 // NodeStatusResponse responds with a node's status.
 type NodeStatusResponse struct {
+	Height     int64                    `json:"height,omitempty"`
 	NodeStatus *registry_api.NodeStatus `json:"result"`
 }
 
 // RegistryGenesisResponse responds with genesis state of registry
 type RegistryGenesisResponse struct {
+	Height          int64                 `json:"height,omitempty"`
 	GenesisRegistry *registry_api.Genesis `json:"result"`
 }
 
@@ -144,52 +176,75 @@ type NodelistResponse struct {
 
 // RuntimeResponse responds with single Runtime
 type RuntimeResponse struct {
+	Height  int64                 `json:"height,omitempty"`
 	Runtime *registry_api.Runtime `json:"result"`
 }
 
 // RuntimesResponse responds with Multiple runtimes
 type RuntimesResponse struct {
+	Height   int64                   `json:"height,omitempty"`
 	Runtimes []*registry_api.Runtime `json:"result"`
 }
 
 // NodesResponse responding with Multiple Nodes
 type NodesResponse struct {
-	Nodes []*common_node.Node `json:"result"`
+	Height int64               `json:"height,omitempty"`
+	Nodes  []*common_node.Node `json:"result"`
 }
 
 // EntitiesResponse responding with Multiple entities
 type EntitiesResponse struct {
+	Height   int64                   `json:"height,omitempty"`
 	Entities []*common_entity.Entity `json:"result"`
 }
 
 // TransactionsResponse responds with all transactions in block
 type TransactionsResponse struct {
+	Height       int64    `json:"height,omitempty"`
 	Transactions [][]byte `json:"result"`
 }
 
 // BlockHeaderResponse responds with Tendermint Header Type
 type BlockHeaderResponse struct {
+	Height    int64              `json:"height,omitempty"`
 	BlkHeader *mint_types.Header `json:"result"`
 }
 
 // BlockLastCommitResponse responds with Tendermint Last Commit Type
 type BlockLastCommitResponse struct {
+	Height        int64              `json:"height,omitempty"`
 	BlkLastCommit *mint_types.Commit `json:"result"`
 }
 
 // BlockResponse responds with custom Block response with an unmarshalled
 // message
 type BlockResponse struct {
-	Blk *consensus_api.Block `json:"result"`
+	Height int64                `json:"height,omitempty"`
+	Blk    *consensus_api.Block `json:"result"`
+}
+
+// NewBlock is summary of a block sent to block subscribers
+type NewBlock struct {
+	Height          int64     `json:"height"`
+	Hash            []byte    `json:"hash"`
+	Time            time.Time `json:"time"`
+	NumTransactions int       `json:"num_transactions"`
+}
+
+// NewBlockResponse responds with a newly finalized block
+type NewBlockResponse struct {
+	NewBlock *NewBlock `json:"result"`
 }
 
 // EpochResponse responds with epcoh time
 type EpochResponse struct {
-	Ep beacon_api.EpochTime `json:"result"`
+	Height int64                `json:"height,omitempty"`
+	Ep     beacon_api.EpochTime `json:"result"`
 }
 
 // ConsensusGenesisResponse with consensus Genesis Document
 type ConsensusGenesisResponse struct {
+	Height  int64             `json:"height,omitempty"`
 	GenJSON *gen_api.Document `json:"result"`
 }
 
@@ -210,6 +265,7 @@ type ConnectionsResponse struct {
 
 // ConsensusParametersResponse responds with the staking consensus parameters
 type ConsensusParametersResponse struct {
+	Height              int64                            `json:"height,omitempty"`
 	ConsensusParameters *staking_api.ConsensusParameters `json:"result"`
 }
 
@@ -223,5 +279,505 @@ type GenesisDocumentResponse struct {
 	GenesisDocument *document_api.Document `json:"result"`
 }
 
+// DecodedTransaction is a consensus transaction decoded from its CBOR form
+type DecodedTransaction struct {
+	Hash           common_hash.Hash           `json:"hash"`
+	Signer         common_signature.PublicKey `json:"signer"`
+	SignerAddress  staking_api.Address        `json:"signer_address"`
+	ValidSignature bool                       `json:"valid_signature"`
+	Nonce          uint64                     `json:"nonce"`
+	Fee            *transaction_api.Fee       `json:"fee,omitempty"`
+	Method         transaction_api.MethodName `json:"method"`
+	Body           interface{}                `json:"body,omitempty"`
+	RawBody        []byte                     `json:"raw_body,omitempty"`
+	Error          string                     `json:"error,omitempty"`
+}
+
+// DecodedTransactionsResponse responds with decoded transactions of a block
+type DecodedTransactionsResponse struct {
+	Height       int64                 `json:"height,omitempty"`
+	Transactions []*DecodedTransaction `json:"result"`
+}
+
+// TransactionWithResult is a decoded transaction with result of executing it
+type TransactionWithResult struct {
+	Transaction *DecodedTransaction  `json:"transaction"`
+	Success     bool                 `json:"success"`
+	Error       *results_api.Error   `json:"error,omitempty"`
+	Events      []*results_api.Event `json:"events"`
+}
+
+// TransactionsWithResultsResponse responds with transactions of a block
+// together with their results
+type TransactionsWithResultsResponse struct {
+	Height       int64                    `json:"height,omitempty"`
+	Transactions []*TransactionWithResult `json:"result"`
+}
+
+// SubmittedTransaction is result of submitting a transaction to a node
+type SubmittedTransaction struct {
+	Hash    common_hash.Hash   `json:"hash"`
+	Success bool               `json:"success"`
+	Error   *results_api.Error `json:"error,omitempty"`
+}
+
+// SubmittedTransactionResponse responds with result of submitting a
+// transaction
+type SubmittedTransactionResponse struct {
+	SubmittedTransaction *SubmittedTransaction `json:"result"`
+}
+
+// UnsignedTransaction is a transaction ready to be signed by an external
+// signer. Fee of transaction is its gas multiplied by gas price given.
+type UnsignedTransaction struct {
+	Signer           common_signature.PublicKey   `json:"signer"`
+	SignerAddress    staking_api.Address          `json:"signer_address"`
+	Transaction      *transaction_api.Transaction `json:"transaction"`
+	GasPrice         common_quantity.Quantity     `json:"gas_price"`
+	Body             interface{}                  `json:"body"`
+	RawTransaction   []byte                       `json:"untrusted_raw_value"`
+	SignatureContext string                       `json:"signature_context"`
+	SigningHash      []byte                       `json:"signing_hash"`
+}
+
+// UnsignedTransactionResponse responds with an unsigned transaction
+type UnsignedTransactionResponse struct {
+	UnsignedTransaction *UnsignedTransaction `json:"result"`
+}
+
+// IndexedBlock holds everything indexed at a height
+type IndexedBlock struct {
+	Block          *consensus_api.Block     `json:"block"`
+	Transactions   []*TransactionWithResult `json:"transactions"`
+	StakingEvents  []*staking_api.Event     `json:"staking_events"`
+	RegistryEvents []*registry_api.Event    `json:"registry_events"`
+}
+
+// IndexedBlockResponse responds with a block read from index
+type IndexedBlockResponse struct {
+	Height       int64         `json:"height,omitempty"`
+	IndexedBlock *IndexedBlock `json:"result"`
+}
+
+// IndexerGap is a range of heights skipped by indexer as node no longer
+// retained them
+type IndexerGap struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// IndexerStatus holds node followed by indexer, heights indexed and gaps
+// within them
+type IndexerStatus struct {
+	NodeName    string        `json:"node_name"`
+	FirstHeight int64         `json:"first_height"`
+	Checkpoint  int64         `json:"checkpoint"`
+	Gaps        []*IndexerGap `json:"gaps"`
+}
+
+// IndexerStatusResponse responds with status of indexer
+type IndexerStatusResponse struct {
+	IndexerStatus *IndexerStatus `json:"result"`
+}
+
+// AccountHistoryItem is a staking event or transaction in the history of
+// an account
+type AccountHistoryItem struct {
+	Height      int64                  `json:"height"`
+	Kind        string                 `json:"kind"`
+	Index       int                    `json:"index"`
+	EventKind   string                 `json:"event_kind,omitempty"`
+	Event       *staking_api.Event     `json:"event,omitempty"`
+	Transaction *TransactionWithResult `json:"transaction,omitempty"`
+}
+
+// AccountHistoryResponse responds with a page of history of an account and
+// cursor of next page
+type AccountHistoryResponse struct {
+	History []*AccountHistoryItem `json:"result"`
+	Next    string                `json:"next,omitempty"`
+}
+
+// AccountSeriesPoint holds balances of an account at a height
+type AccountSeriesPoint struct {
+	Height    int64                    `json:"height"`
+	Time      time.Time                `json:"time"`
+	Balance   common_quantity.Quantity `json:"balance"`
+	Escrow    common_quantity.Quantity `json:"escrow"`
+	Debonding common_quantity.Quantity `json:"debonding"`
+}
+
+// AccountSeriesResponse responds with balances of an account sampled over a
+// range of heights
+type AccountSeriesResponse struct {
+	Series []*AccountSeriesPoint `json:"result"`
+}
+
+// DelegationValue holds a delegation or debonding delegation valued in base
+// units and in tokens
+type DelegationValue struct {
+	Delegator      staking_api.Address      `json:"delegator"`
+	Validator      staking_api.Address      `json:"validator"`
+	Shares         common_quantity.Quantity `json:"shares"`
+	Amount         common_quantity.Quantity `json:"amount"`
+	Tokens         string                   `json:"tokens"`
+	PoolPercentage float64                  `json:"pool_percentage"`
+	DebondEndTime  *beacon_api.EpochTime    `json:"debond_end_time,omitempty"`
+}
+
+// DelegationTotal holds sum of active and debonding delegations of an
+// address
+type DelegationTotal struct {
+	Address         staking_api.Address      `json:"address"`
+	Active          common_quantity.Quantity `json:"active"`
+	ActiveTokens    string                   `json:"active_tokens"`
+	Debonding       common_quantity.Quantity `json:"debonding"`
+	DebondingTokens string                   `json:"debonding_tokens"`
+	Total           common_quantity.Quantity `json:"total"`
+	TotalTokens     string                   `json:"total_tokens"`
+}
+
+// DelegationValues holds delegations to or from an address valued in base
+// units and in tokens, summed per counterpart and in total
+type DelegationValues struct {
+	TokenSymbol string             `json:"token_symbol"`
+	Active      []*DelegationValue `json:"active"`
+	Debonding   []*DelegationValue `json:"debonding"`
+	Totals      []*DelegationTotal `json:"totals"`
+	Total       *DelegationTotal   `json:"total"`
+}
+
+// DelegationValuesResponse responds with valued delegations
+type DelegationValuesResponse struct {
+	Height           int64             `json:"height,omitempty"`
+	DelegationValues *DelegationValues `json:"result"`
+}
+
+// DebondingRelease holds debonding delegations released at the start of an
+// epoch and estimated time at which epoch starts
+type DebondingRelease struct {
+	Epoch         beacon_api.EpochTime     `json:"epoch"`
+	EstimatedTime time.Time                `json:"estimated_time"`
+	Amount        common_quantity.Quantity `json:"amount"`
+	Tokens        string                   `json:"tokens"`
+	Delegations   []*DelegationValue       `json:"delegations"`
+}
+
+// DebondingSchedule holds debonding delegations of an address grouped by
+// epoch at which they are released
+type DebondingSchedule struct {
+	TokenSymbol   string                   `json:"token_symbol"`
+	CurrentEpoch  beacon_api.EpochTime     `json:"current_epoch"`
+	EpochInterval int64                    `json:"epoch_interval"`
+	BlockTime     float64                  `json:"block_time"`
+	Releases      []*DebondingRelease      `json:"releases"`
+	Total         common_quantity.Quantity `json:"total"`
+	TotalTokens   string                   `json:"total_tokens"`
+}
+
+// DebondingScheduleResponse responds with a debonding schedule
+type DebondingScheduleResponse struct {
+	Height            int64              `json:"height,omitempty"`
+	DebondingSchedule *DebondingSchedule `json:"result"`
+}
+
+// RewardAmounts holds a reward split into commission taken by validator
+// and remainder added to escrow of delegators
+type RewardAmounts struct {
+	Gross            common_quantity.Quantity `json:"gross"`
+	GrossTokens      string                   `json:"gross_tokens"`
+	Commission       common_quantity.Quantity `json:"commission"`
+	CommissionTokens string                   `json:"commission_tokens"`
+	Net              common_quantity.Quantity `json:"net"`
+	NetTokens        string                   `json:"net_tokens"`
+}
+
+// RewardEstimate holds rewards estimated for an escrow account, or for an
+// amount delegated to it, per epoch and over a year
+type RewardEstimate struct {
+	Amount       common_quantity.Quantity `json:"amount"`
+	AmountTokens string                   `json:"amount_tokens"`
+	Epoch        *RewardAmounts           `json:"epoch"`
+	Annual       *RewardAmounts           `json:"annual"`
+}
+
+// RewardAssumptions holds inputs used to estimate rewards
+type RewardAssumptions struct {
+	EpochDuration           float64                  `json:"epoch_duration"`
+	EpochsPerYear           int64                    `json:"epochs_per_year"`
+	RewardFactorEpochSigned common_quantity.Quantity `json:"reward_factor_epoch_signed"`
+	RewardScale             common_quantity.Quantity `json:"reward_scale"`
+	RewardScheduleEnd       beacon_api.EpochTime     `json:"reward_schedule_end"`
+	CommonPool              common_quantity.Quantity `json:"common_pool"`
+	TotalSupply             common_quantity.Quantity `json:"total_supply"`
+	Notes                   []string                 `json:"notes"`
+}
+
+// ValidatorRewards holds rewards and yield estimated for an escrow account
+type ValidatorRewards struct {
+	TokenSymbol    string               `json:"token_symbol"`
+	Validator      staking_api.Address  `json:"validator"`
+	CurrentEpoch   beacon_api.EpochTime `json:"current_epoch"`
+	CommissionRate float64              `json:"commission_rate"`
+	GrossAPR       float64              `json:"gross_apr"`
+	APR            float64              `json:"apr"`
+	APY            float64              `json:"apy"`
+	Escrow         *RewardEstimate      `json:"escrow"`
+	Delegation     *RewardEstimate      `json:"delegation,omitempty"`
+	Assumptions    *RewardAssumptions   `json:"assumptions"`
+}
+
+// ValidatorRewardsResponse responds with rewards estimated for a validator
+type ValidatorRewardsResponse struct {
+	Height           int64             `json:"height,omitempty"`
+	ValidatorRewards *ValidatorRewards `json:"result"`
+}
+
+// RealisedReward holds reward realised by a delegation to a validator at
+// the start of an epoch, in signed base units as slashing can make it
+// negative
+type RealisedReward struct {
+	Epoch        beacon_api.EpochTime     `json:"epoch"`
+	Height       int64                    `json:"height"`
+	Time         time.Time                `json:"time"`
+	Validator    staking_api.Address      `json:"validator"`
+	Value        common_quantity.Quantity `json:"value"`
+	Reward       string                   `json:"reward"`
+	RewardTokens string                   `json:"reward_tokens"`
+}
+
+// RealisedRewardTotal holds rewards realised by delegations to a validator
+type RealisedRewardTotal struct {
+	Validator    staking_api.Address `json:"validator"`
+	Reward       string              `json:"reward"`
+	RewardTokens string              `json:"reward_tokens"`
+}
+
+// RealisedRewards holds rewards realised by delegations of a delegator over
+// a range of heights, per epoch and in total
+type RealisedRewards struct {
+	TokenSymbol string                 `json:"token_symbol"`
+	Delegator   staking_api.Address    `json:"delegator"`
+	FromHeight  int64                  `json:"from_height"`
+	ToHeight    int64                  `json:"to_height"`
+	Epochs      []*RealisedReward      `json:"epochs"`
+	Totals      []*RealisedRewardTotal `json:"totals"`
+	Total       string                 `json:"total"`
+	TotalTokens string                 `json:"total_tokens"`
+}
+
+// RealisedRewardsResponse responds with rewards realised by a delegator
+type RealisedRewardsResponse struct {
+	RealisedRewards *RealisedRewards `json:"result"`
+}
+
+// CommissionRateStep holds a step of a commission schedule as a percentage
+// together with estimated time at which it starts
+type CommissionRateStep struct {
+	Start         beacon_api.EpochTime     `json:"start"`
+	EstimatedTime time.Time                `json:"estimated_time"`
+	Current       bool                     `json:"current"`
+	Rate          common_quantity.Quantity `json:"rate"`
+	RatePercent   float64                  `json:"rate_percent"`
+}
+
+// CommissionBoundStep holds a bound step of a commission schedule as
+// percentages together with estimated time at which it starts
+type CommissionBoundStep struct {
+	Start          beacon_api.EpochTime     `json:"start"`
+	EstimatedTime  time.Time                `json:"estimated_time"`
+	Current        bool                     `json:"current"`
+	RateMin        common_quantity.Quantity `json:"rate_min"`
+	RateMinPercent float64                  `json:"rate_min_percent"`
+	RateMax        common_quantity.Quantity `json:"rate_max"`
+	RateMaxPercent float64                  `json:"rate_max_percent"`
+}
+
+// CommissionAmendment holds schedule resulting from a pending amendment and
+// the rule it violates, if any
+type CommissionAmendment struct {
+	Valid     bool                   `json:"valid"`
+	Violation string                 `json:"violation,omitempty"`
+	Rates     []*CommissionRateStep  `json:"rates"`
+	Bounds    []*CommissionBoundStep `json:"bounds"`
+}
+
+// CommissionSchedule holds commission schedule of an escrow account in
+// effect and scheduled from the current epoch on, the rules it is checked
+// against and the rule it violates, if any
+type CommissionSchedule struct {
+	Address       staking_api.Address                 `json:"address"`
+	CurrentEpoch  beacon_api.EpochTime                `json:"current_epoch"`
+	EpochInterval int64                               `json:"epoch_interval"`
+	BlockTime     float64                             `json:"block_time"`
+	CurrentRate   *float64                            `json:"current_rate"`
+	CurrentBound  *CommissionBoundStep                `json:"current_bound"`
+	Rates         []*CommissionRateStep               `json:"rates"`
+	Bounds        []*CommissionBoundStep              `json:"bounds"`
+	Rules         staking_api.CommissionScheduleRules `json:"rules"`
+	Valid         bool                                `json:"valid"`
+	Violation     string                              `json:"violation,omitempty"`
+	Amendment     *CommissionAmendment                `json:"amendment,omitempty"`
+}
+
+// CommissionScheduleResponse responds with a commission schedule
+type CommissionScheduleResponse struct {
+	Height             int64               `json:"height,omitempty"`
+	CommissionSchedule *CommissionSchedule `json:"result"`
+}
+
+// RichListAccount holds balances of an account and its share of total
+// supply at its rank in a rich list
+type RichListAccount struct {
+	Rank        int                      `json:"rank"`
+	Address     staking_api.Address      `json:"address"`
+	Balance     common_quantity.Quantity `json:"balance"`
+	Escrow      common_quantity.Quantity `json:"escrow"`
+	Debonding   common_quantity.Quantity `json:"debonding"`
+	Total       common_quantity.Quantity `json:"total"`
+	TotalTokens string                   `json:"total_tokens"`
+	Share       float64                  `json:"share"`
+}
+
+// TopShare holds share of total supply held by a number of richest accounts
+type TopShare struct {
+	Accounts int     `json:"accounts"`
+	Share    float64 `json:"share"`
+}
+
+// StakeDistribution holds statistics of distribution of tokens over all
+// accounts
+type StakeDistribution struct {
+	Accounts        int                      `json:"accounts"`
+	TotalSupply     common_quantity.Quantity `json:"total_supply"`
+	CommonPool      common_quantity.Quantity `json:"common_pool"`
+	CommonPoolShare float64                  `json:"common_pool_share"`
+	Gini            float64                  `json:"gini"`
+	TopShares       []*TopShare              `json:"top_shares"`
+}
+
+// RichList holds a page of accounts ranked by balance together with
+// statistics of distribution of tokens
+type RichList struct {
+	TokenSymbol  string             `json:"token_symbol"`
+	Sort         string             `json:"sort"`
+	Order        string             `json:"order"`
+	Offset       int                `json:"offset"`
+	Limit        int                `json:"limit"`
+	Accounts     []*RichListAccount `json:"accounts"`
+	Distribution *StakeDistribution `json:"distribution"`
+}
+
+// RichListResponse responds with a rich list
+type RichListResponse struct {
+	Height   int64     `json:"height,omitempty"`
+	RichList *RichList `json:"result"`
+}
+
+// ValidatorOverview holds scheduler, registry and staking data of a
+// validator at its rank by voting power
+type ValidatorOverview struct {
+	Rank               int                        `json:"rank"`
+	NodeID             common_signature.PublicKey `json:"node_id"`
+	EntityID           common_signature.PublicKey `json:"entity_id"`
+	EntityAddress      staking_api.Address        `json:"entity_address"`
+	ConsensusPublicKey common_signature.PublicKey `json:"consensus_public_key"`
+	TendermintAddress  tmed.Address               `json:"tendermint_address"`
+	VotingPower        int64                      `json:"voting_power"`
+	VotingPowerShare   float64                    `json:"voting_power_share"`
+	Escrow             common_quantity.Quantity   `json:"escrow"`
+	EscrowTokens       string                     `json:"escrow_tokens"`
+	CommissionRate     *float64                   `json:"commission_rate"`
+	Delegators         int                        `json:"delegators"`
+}
+
+// ValidatorOverviews holds every validator of an epoch ranked by voting
+// power
+type ValidatorOverviews struct {
+	TokenSymbol      string               `json:"token_symbol"`
+	Epoch            beacon_api.EpochTime `json:"epoch"`
+	TotalVotingPower int64                `json:"total_voting_power"`
+	Validators       []*ValidatorOverview `json:"validators"`
+}
+
+// ValidatorOverviewsResponse responds with an overview of validators
+type ValidatorOverviewsResponse struct {
+	Height             int64               `json:"height,omitempty"`
+	ValidatorOverviews *ValidatorOverviews `json:"result"`
+}
+
+// ValidatorUptime holds number of blocks a validator was expected to sign
+// over a window and how many of them it signed and missed
+type ValidatorUptime struct {
+	NodeID              common_signature.PublicKey `json:"node_id"`
+	EntityID            common_signature.PublicKey `json:"entity_id"`
+	TendermintAddress   tmed.Address               `json:"tendermint_address"`
+	Expected            int64                      `json:"expected"`
+	Signed              int64                      `json:"signed"`
+	Missed              int64                      `json:"missed"`
+	Uptime              float64                    `json:"uptime"`
+	LongestMissedStreak int64                      `json:"longest_missed_streak"`
+	CurrentMissedStreak int64                      `json:"current_missed_streak"`
+}
+
+// ValidatorUptimes holds signatures of validators over a window of blocks,
+// per validator and for the whole set
+type ValidatorUptimes struct {
+	FromHeight   int64              `json:"from_height"`
+	ToHeight     int64              `json:"to_height"`
+	Blocks       int64              `json:"blocks"`
+	Unattributed int64              `json:"unattributed"`
+	Expected     int64              `json:"expected"`
+	Signed       int64              `json:"signed"`
+	Missed       int64              `json:"missed"`
+	Uptime       float64            `json:"uptime"`
+	Validators   []*ValidatorUptime `json:"validators"`
+}
+
+// ValidatorUptimesResponse responds with uptime of validators
+type ValidatorUptimesResponse struct {
+	Height           int64             `json:"height,omitempty"`
+	ValidatorUptimes *ValidatorUptimes `json:"result"`
+}
+
+// NodeBlock holds height and time of a block
+type NodeBlock struct {
+	Height int64     `json:"height"`
+	Time   time.Time `json:"time"`
+}
+
+// NodeSigning holds blocks a node signed, missed and proposed over a window
+// of blocks, with most recent proposals and misses listed first
+type NodeSigning struct {
+	NodeID              common_signature.PublicKey `json:"node_id"`
+	ConsensusPublicKey  common_signature.PublicKey `json:"consensus_public_key"`
+	TendermintAddress   tmed.Address               `json:"tendermint_address"`
+	FromHeight          int64                      `json:"from_height"`
+	ToHeight            int64                      `json:"to_height"`
+	Blocks              int64                      `json:"blocks"`
+	Unattributed        int64                      `json:"unattributed"`
+	Expected            int64                      `json:"expected"`
+	Signed              int64                      `json:"signed"`
+	Missed              int64                      `json:"missed"`
+	Uptime              float64                    `json:"uptime"`
+	LongestMissedStreak int64                      `json:"longest_missed_streak"`
+	CurrentMissedStreak int64                      `json:"current_missed_streak"`
+	Proposed            int64                      `json:"proposed"`
+	ExpectedProposals   float64                    `json:"expected_proposals"`
+	Proposals           []*NodeBlock               `json:"proposals"`
+	Misses              []*NodeBlock               `json:"misses"`
+}
+
+// NodeSigningResponse responds with signing and proposing of a node
+type NodeSigningResponse struct {
+	Height      int64        `json:"height,omitempty"`
+	NodeSigning *NodeSigning `json:"result"`
+}
+
+// CacheStatsResponse responds with statistics of response cache usage
+type CacheStatsResponse struct {
+	CacheStats *cache.Stats `json:"result"`
+}
+
 // SuccessResponsed Assinging Variable Responses that do not need to be changed.
 var SuccessResponsed = SuccessResponse{Result: "pong"}
@@ -0,0 +1,312 @@
+package router
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gorilla/mux"
+)
+
+// Responses smaller than this are sent uncompressed
+const compressMinSize = 1024
+
+// Content encodings supported by compression, in order of preference
+var compressEncodings = []string{"br", "gzip"}
+
+// compressor compresses responses with a fixed compression level, reusing
+// encoders between requests.
+type compressor struct {
+	gzipLevel   int
+	brotliLevel int
+	gzipPool    sync.Pool
+	brotliPool  sync.Pool
+}
+
+// compressionMiddleware returns middleware compressing responses using
+// encoding negotiated by Accept-Encoding. Level ranges from 1 (fastest) to
+// 9 (smallest), 0 disables compression and -1 uses default levels.
+func compressionMiddleware(level int) mux.MiddlewareFunc {
+	if level == 0 {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	c := &compressor{gzipLevel: level, brotliLevel: level}
+	if level < 0 {
+		c.gzipLevel = gzip.DefaultCompression
+		c.brotliLevel = brotli.DefaultCompression
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter,
+			r *http.Request) {
+
+			// WebSocket upgrades take over the connection
+			if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, c: c,
+				encoding: encoding}
+			defer cw.Close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// parseCompressionLevel parses compression level found in main config
+func parseCompressionLevel(recvLevel string) (int, error) {
+	if len(recvLevel) == 0 {
+		return -1, nil
+	}
+
+	level, err := strconv.Atoi(recvLevel)
+	if err != nil || level < 0 || level > 9 {
+		return 0, errors.New("compression level needs to be " +
+			"between 0 and 9")
+	}
+	return level, nil
+}
+
+// negotiateEncoding returns most preferred supported encoding accepted by
+// Accept-Encoding header, or an empty string if none is.
+func negotiateEncoding(acceptEncoding string) string {
+	best, bestQ := "", 0.0
+	wildcardQ := -1.0
+	accepted := make(map[string]float64)
+
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+		if name == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			value, err := strconv.ParseFloat(param[2:], 64)
+			if err != nil || value < 0 || value > 1 {
+				value = 0
+			}
+			q = value
+		}
+
+		if name == "*" {
+			wildcardQ = q
+			continue
+		}
+		accepted[name] = q
+	}
+
+	for _, encoding := range compressEncodings {
+		q, ok := accepted[encoding]
+		if !ok {
+			q = wildcardQ
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// compressWriter buffers start of response to decide whether it is worth
+// compressing, then writes it through chosen encoder.
+type compressWriter struct {
+	http.ResponseWriter
+	c        *compressor
+	encoding string
+
+	buf         []byte
+	status      int
+	decided     bool
+	hijacked    bool
+	encoder     io.WriteCloser
+	wroteHeader bool
+}
+
+// WriteHeader records status code, sending it once compression is decided
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.status != 0 {
+		return
+	}
+	cw.status = status
+
+	// Responses without a body are never compressed
+	if status < http.StatusOK || status == http.StatusNoContent ||
+		status == http.StatusNotModified {
+		cw.decide(false)
+	}
+}
+
+// Write buffers data until enough of it is written to decide on compression
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.decided {
+		return cw.write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= compressMinSize {
+		if err := cw.decide(cw.compressible()); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush sends data written so far to the client, deciding on compression
+// if not done yet since a flushing handler is likely streaming.
+func (cw *compressWriter) Flush() {
+	if cw.hijacked {
+		return
+	}
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.decided {
+		if err := cw.decide(cw.compressible()); err != nil {
+			return
+		}
+	}
+	if f, ok := cw.encoder.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets handlers take over the connection
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := cw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not support hijacking")
+	}
+	cw.hijacked = true
+	return h.Hijack()
+}
+
+// Close sends any buffered data and finishes compressed stream
+func (cw *compressWriter) Close() error {
+	if cw.hijacked {
+		return nil
+	}
+	if !cw.decided {
+
+		// Response ended before reaching minimum size
+		if cw.status == 0 && len(cw.buf) == 0 {
+			return nil
+		}
+		if cw.status == 0 {
+			cw.WriteHeader(http.StatusOK)
+		}
+		if err := cw.decide(false); err != nil {
+			return err
+		}
+	}
+	if cw.encoder == nil {
+		return nil
+	}
+
+	err := cw.encoder.Close()
+	cw.c.release(cw.encoding, cw.encoder)
+	cw.encoder = nil
+	return err
+}
+
+// compressible checks whether response headers allow compression
+func (cw *compressWriter) compressible() bool {
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	// Server-Sent Events have to reach the client as they are written
+	contentType := header.Get("Content-Type")
+	return !strings.HasPrefix(contentType, "text/event-stream")
+}
+
+// decide sends headers and buffered data, compressing them if asked to
+func (cw *compressWriter) decide(compress bool) error {
+	cw.decided = true
+	header := cw.Header()
+	if compress {
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		cw.encoder = cw.c.acquire(cw.encoding, cw.ResponseWriter)
+	}
+	if cw.compressible() || compress {
+		header.Add("Vary", "Accept-Encoding")
+	}
+
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		cw.ResponseWriter.WriteHeader(cw.status)
+	}
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	buf := cw.buf
+	cw.buf = nil
+	_, err := cw.write(buf)
+	return err
+}
+
+// write writes data through encoder if response is compressed
+func (cw *compressWriter) write(p []byte) (int, error) {
+	if cw.encoder != nil {
+		return cw.encoder.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// acquire returns encoder for encoding writing to w
+func (c *compressor) acquire(encoding string, w io.Writer) io.WriteCloser {
+	switch encoding {
+	case "br":
+		if bw, ok := c.brotliPool.Get().(*brotli.Writer); ok {
+			bw.Reset(w)
+			return bw
+		}
+		return brotli.NewWriterLevel(w, c.brotliLevel)
+	default:
+		if gw, ok := c.gzipPool.Get().(*gzip.Writer); ok {
+			gw.Reset(w)
+			return gw
+		}
+		gw, _ := gzip.NewWriterLevel(w, c.gzipLevel)
+		return gw
+	}
+}
+
+// release returns encoder to be reused by later responses
+func (c *compressor) release(encoding string, encoder io.WriteCloser) {
+	switch encoding {
+	case "br":
+		c.brotliPool.Put(encoder)
+	default:
+		c.gzipPool.Put(encoder)
+	}
+}
@@ -0,0 +1,205 @@
+package router
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Body large enough to be compressed
+var largeBody = strings.Repeat(`{"result":"Unicorn"}`, 200)
+
+func serveCompressed(acceptEncoding string, h http.HandlerFunc) *http.Response {
+	req, _ := http.NewRequest("GET", "/api/ping", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+
+	rr := httptest.NewRecorder()
+	compressionMiddleware(-1)(h).ServeHTTP(rr, req)
+	return rr.Result()
+}
+
+func writeBody(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}
+}
+
+func Test_NegotiateEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":                        "",
+		"identity":                "",
+		"gzip":                    "gzip",
+		"gzip, deflate, br":       "br",
+		"br;q=0.5, gzip":          "gzip",
+		"br;q=0, gzip;q=0":        "",
+		"*":                       "br",
+		"*;q=0.1, gzip;q=0.5":     "gzip",
+		"BR;q=0.8, GZIP;q=0.8":    "br",
+		"gzip;q=Unicorn, br;q=.2": "br",
+	}
+
+	for acceptEncoding, expected := range tests {
+		if encoding := negotiateEncoding(acceptEncoding); encoding !=
+			expected {
+			t.Errorf("negotiateEncoding(%q) returned wrong encoding: "+
+				"got %v want %v", acceptEncoding, encoding, expected)
+		}
+	}
+}
+
+func Test_ParseCompressionLevel(t *testing.T) {
+	if level, err := parseCompressionLevel(""); err != nil || level != -1 {
+		t.Errorf("parseCompressionLevel returned wrong level: got %v "+
+			"want %v", level, -1)
+	}
+	if level, err := parseCompressionLevel("9"); err != nil || level != 9 {
+		t.Errorf("parseCompressionLevel returned wrong level: got %v "+
+			"want %v", level, 9)
+	}
+	if _, err := parseCompressionLevel("Unicorn"); err == nil {
+		t.Errorf("parseCompressionLevel accepted invalid level")
+	}
+	if _, err := parseCompressionLevel("10"); err == nil {
+		t.Errorf("parseCompressionLevel accepted invalid level")
+	}
+}
+
+func Test_Compression_Gzip(t *testing.T) {
+	resp := serveCompressed("gzip", writeBody(largeBody))
+
+	if encoding := resp.Header.Get("Content-Encoding"); encoding != "gzip" {
+		t.Fatalf("handler returned wrong content encoding: got %v want %v",
+			encoding, "gzip")
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read gzipped body : %v", err)
+	}
+	body, _ := ioutil.ReadAll(gz)
+	if string(body) != largeBody {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			string(body), largeBody)
+	}
+}
+
+func Test_Compression_Brotli(t *testing.T) {
+	resp := serveCompressed("gzip, br", writeBody(largeBody))
+
+	if encoding := resp.Header.Get("Content-Encoding"); encoding != "br" {
+		t.Fatalf("handler returned wrong content encoding: got %v want %v",
+			encoding, "br")
+	}
+
+	body, _ := ioutil.ReadAll(brotli.NewReader(resp.Body))
+	if string(body) != largeBody {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			string(body), largeBody)
+	}
+}
+
+func Test_Compression_SmallResponse(t *testing.T) {
+	expected := `{"result":"pong"}`
+	resp := serveCompressed("gzip", writeBody(expected))
+
+	if encoding := resp.Header.Get("Content-Encoding"); encoding != "" {
+		t.Errorf("handler returned wrong content encoding: got %v want "+
+			"none", encoding)
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	if string(body) != expected {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			string(body), expected)
+	}
+}
+
+func Test_Compression_NotAccepted(t *testing.T) {
+	resp := serveCompressed("", writeBody(largeBody))
+
+	if encoding := resp.Header.Get("Content-Encoding"); encoding != "" {
+		t.Errorf("handler returned wrong content encoding: got %v want "+
+			"none", encoding)
+	}
+}
+
+func Test_Compression_AlreadyEncoded(t *testing.T) {
+	resp := serveCompressed("gzip", func(w http.ResponseWriter,
+		r *http.Request) {
+		w.Header().Set("Content-Encoding", "identity")
+		w.Write([]byte(largeBody))
+	})
+
+	if encoding := resp.Header.Get("Content-Encoding"); encoding !=
+		"identity" {
+		t.Errorf("handler returned wrong content encoding: got %v want %v",
+			encoding, "identity")
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	if string(body) != largeBody {
+		t.Errorf("handler returned unexpected body")
+	}
+}
+
+func Test_Compression_EventStream(t *testing.T) {
+	resp := serveCompressed("gzip", func(w http.ResponseWriter,
+		r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("retry: 5000\n\n"))
+		w.(http.Flusher).Flush()
+		w.Write([]byte(largeBody))
+	})
+
+	if encoding := resp.Header.Get("Content-Encoding"); encoding != "" {
+		t.Errorf("handler returned wrong content encoding: got %v want "+
+			"none", encoding)
+	}
+}
+
+func Test_Compression_Streaming(t *testing.T) {
+	resp := serveCompressed("gzip", func(w http.ResponseWriter,
+		r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Write([]byte(largeBody[:10]))
+		w.(http.Flusher).Flush()
+		w.Write([]byte(largeBody[10:]))
+	})
+
+	if encoding := resp.Header.Get("Content-Encoding"); encoding != "gzip" {
+		t.Fatalf("handler returned wrong content encoding: got %v want %v",
+			encoding, "gzip")
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read gzipped body : %v", err)
+	}
+	body, _ := ioutil.ReadAll(gz)
+	if string(body) != largeBody {
+		t.Errorf("handler returned unexpected body: got %v want %v",
+			string(body), largeBody)
+	}
+}
+
+func Test_Compression_WebSocket(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/consensus/watchblocks", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("Upgrade", "websocket")
+
+	rr := httptest.NewRecorder()
+	compressionMiddleware(-1)(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if _, ok := w.(*compressWriter); ok {
+			t.Errorf("WebSocket upgrade was wrapped for compression")
+		}
+	})).ServeHTTP(rr, req)
+}
@@ -1,8 +1,12 @@
 package router
 
 import (
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -40,81 +44,167 @@ func StartServer() error {
 	apiPort := mainConf["api_server"]["port"]
 	lgr.Info.Println("Loaded port : ", apiPort)
 
+	// Load compression level of responses, falling back to default
+	compressionLevel, err5 := parseCompressionLevel(
+		mainConf["api_server"]["compression_level"])
+	if err5 != nil {
+		lgr.Error.Println("Loading of compression level has failed, "+
+			"using default : ", err5)
+		compressionLevel = -1
+	}
+
+	// Load response cache configuration, caching is disabled on failure
+	cacheSize, cacheTTL, err6 := parseCacheConfig(mainConf["api_server"])
+	if err6 != nil {
+		lgr.Error.Println("Loading of cache configuration has failed, "+
+			"caching is disabled : ", err6)
+	} else if cacheSize > 0 {
+		handler.InitCache(cacheSize, cacheTTL)
+		lgr.Info.Println("Loaded cache size in bytes : ", cacheSize)
+	}
+
+	// Start indexer if a node to index is configured
+	indexerNode, indexerPath, indexerStart, err7 := parseIndexerConfig(
+		mainConf["indexer"])
+	if err7 != nil {
+		lgr.Error.Println("Loading of indexer configuration has failed, "+
+			"indexer is disabled : ", err7)
+	} else if len(indexerNode) > 0 {
+		if err := handler.InitIndexer(indexerNode, indexerPath,
+			indexerStart); err != nil {
+			lgr.Error.Println("Starting of indexer has failed : ", err)
+		} else {
+			lgr.Info.Println("Indexing blocks of node : ", indexerNode)
+		}
+	}
+
 	// Router object to handle requests
 	router := mux.NewRouter().StrictSlash(true)
 
+	// Compress responses for clients that accept it
+	router.Use(compressionMiddleware(compressionLevel))
+
 	// Router Handlers to handle General API Calls
 	router.HandleFunc("/api/ping", handler.Pong).Methods("Get")
 	router.HandleFunc("/api/getconnectionslist",
 		handler.GetConnections).Methods("Get")
+	router.HandleFunc("/api/cache/stats",
+		handler.GetCacheStats).Methods("Get")
+	router.HandleFunc("/api/indexer/status",
+		handler.GetIndexerStatus).Methods("Get")
+	router.HandleFunc("/api/indexer/block",
+		handler.GetIndexedBlock).Methods("Get")
 
 	// Router Handlers to handle Consensus API Calls
 	router.HandleFunc("/api/consensus/genesis",
-		handler.GetConsensusStateToGenesis).Methods("Get")
+		handler.Pinned(handler.GetConsensusStateToGenesis)).Methods("Get")
 	router.HandleFunc("/api/consensus/epoch",
-		handler.GetEpoch).Methods("Get")
+		heightBased(handler.GetEpoch)).Methods("Get")
 	router.HandleFunc("/api/consensus/block",
-		handler.GetBlock).Methods("Get")
+		heightBased(handler.GetBlock)).Methods("Get")
 	router.HandleFunc("/api/consensus/status",
 		handler.GetStatus).Methods("Get")
 	router.HandleFunc("/api/consensus/genesisdocument",
 		handler.GetGenesisDocument).Methods("Get")
 	router.HandleFunc("/api/consensus/blockheader",
-		handler.GetBlockHeader).Methods("Get")
+		heightBased(handler.GetBlockHeader)).Methods("Get")
 	router.HandleFunc("/api/consensus/blocklastcommit",
-		handler.GetBlockLastCommit).Methods("Get")
+		heightBased(handler.GetBlockLastCommit)).Methods("Get")
+	router.HandleFunc("/api/consensus/validatoruptimes",
+		heightBased(handler.GetValidatorUptimes)).Methods("Get")
+	router.HandleFunc("/api/consensus/nodesigning",
+		heightBased(handler.GetNodeSigning)).Methods("Get")
 	router.HandleFunc("/api/consensus/pubkeyaddress",
 		handler.PublicKeyToAddress).Methods("Get")
 	router.HandleFunc("/api/consensus/transactions",
-		handler.GetTransactions).Methods("Get")
+		heightBased(handler.GetTransactions)).Methods("Get")
+	router.HandleFunc("/api/consensus/transactionswithresults",
+		heightBased(handler.GetTransactionsWithResults)).Methods("Get")
+	router.HandleFunc("/api/consensus/submittx",
+		handler.Authorized(handler.ScopeSubmit,
+			handler.SubmitTransaction)).Methods("Post")
+	router.HandleFunc("/api/consensus/watchblocks",
+		handler.WatchBlocks).Methods("Get")
 	router.HandleFunc("/api/pingnode",
 		handler.PingNode).Methods("Get")
 
 	// Router Handlers to handle Registry API Calls
 	router.HandleFunc("/api/registry/entities",
-		handler.GetEntities).Methods("Get")
+		heightBased(handler.GetEntities)).Methods("Get")
 	router.HandleFunc("/api/registry/nodes",
-		handler.GetNodes).Methods("Get")
+		heightBased(handler.GetNodes)).Methods("Get")
 	router.HandleFunc("/api/registry/nodestatus",
-		handler.GetNodeStatus).Methods("Get")
+		heightBased(handler.GetNodeStatus)).Methods("Get")
 	router.HandleFunc("/api/registry/events",
-		handler.GetRegistryEvents).Methods("Get")
+		heightBased(handler.GetRegistryEvents)).Methods("Get")
+	router.HandleFunc("/api/registry/watchevents",
+		handler.WatchRegistryEvents).Methods("Get")
 	router.HandleFunc("/api/registry/runtimes",
-		handler.GetRuntimes).Methods("Get")
+		heightBased(handler.GetRuntimes)).Methods("Get")
 	router.HandleFunc("/api/registry/genesis",
-		handler.GetRegistryStateToGenesis).Methods("Get")
+		handler.Pinned(handler.GetRegistryStateToGenesis)).Methods("Get")
 	router.HandleFunc("/api/registry/entity",
-		handler.GetEntity).Methods("Get")
+		heightBased(handler.GetEntity)).Methods("Get")
 	router.HandleFunc("/api/registry/node",
-		handler.GetNode).Methods("Get")
+		heightBased(handler.GetNode)).Methods("Get")
 	router.HandleFunc("/api/registry/runtime",
-		handler.GetRuntime).Methods("Get")
+		heightBased(handler.GetRuntime)).Methods("Get")
 
 	// Router Handlers to handle Staking API Calls
 	router.HandleFunc("/api/staking/totalsupply",
-		handler.GetTotalSupply).Methods("Get")
+		heightBased(handler.GetTotalSupply)).Methods("Get")
 	router.HandleFunc("/api/staking/commonpool",
-		handler.GetCommonPool).Methods("Get")
+		heightBased(handler.GetCommonPool)).Methods("Get")
 	router.HandleFunc("/api/staking/lastblockfees",
-		handler.GetLastBlockFees).Methods("Get")
+		heightBased(handler.GetLastBlockFees)).Methods("Get")
 	router.HandleFunc("/api/staking/genesis",
-		handler.GetStakingStateToGenesis).Methods("Get")
+		handler.Pinned(handler.GetStakingStateToGenesis)).Methods("Get")
 	router.HandleFunc("/api/staking/threshold",
-		handler.GetThreshold).Methods("Get")
+		heightBased(handler.GetThreshold)).Methods("Get")
 	router.HandleFunc("/api/staking/addresses",
-		handler.GetAddresses).Methods("Get")
+		heightBased(handler.GetAddresses)).Methods("Get")
 	router.HandleFunc("/api/staking/publickeytoaddress",
 		handler.GetAddressFromPublicKey).Methods("Get")
 	router.HandleFunc("/api/staking/consensusparameters",
-		handler.GetConsensusParameters).Methods("Get")
+		heightBased(handler.GetConsensusParameters)).Methods("Get")
 	router.HandleFunc("/api/staking/account",
-		handler.GetAccount).Methods("Get")
+		heightBased(handler.GetAccount)).Methods("Get")
 	router.HandleFunc("/api/staking/delegations",
-		handler.GetDelegations).Methods("Get")
+		heightBased(handler.GetDelegations)).Methods("Get")
 	router.HandleFunc("/api/staking/debondingdelegations",
-		handler.GetDebondingDelegations).Methods("Get")
+		heightBased(handler.GetDebondingDelegations)).Methods("Get")
+	router.HandleFunc("/api/staking/delegationsfor",
+		heightBased(handler.GetDelegationsFor)).Methods("Get")
+	router.HandleFunc("/api/staking/debondingdelegationsfor",
+		heightBased(handler.GetDebondingDelegationsFor)).Methods("Get")
+	router.HandleFunc("/api/staking/delegationvalues",
+		heightBased(handler.GetDelegationValues)).Methods("Get")
+	router.HandleFunc("/api/staking/debondingschedule",
+		heightBased(handler.GetDebondingSchedule)).Methods("Get")
+	router.HandleFunc("/api/staking/validatorrewards",
+		heightBased(handler.GetValidatorRewards)).Methods("Get")
+	router.HandleFunc("/api/staking/realisedrewards",
+		handler.GetRealisedRewards).Methods("Get")
+	router.HandleFunc("/api/staking/commissionschedule",
+		heightBased(handler.GetCommissionSchedule)).Methods("Get")
+	router.HandleFunc("/api/staking/richlist",
+		heightBased(handler.GetRichList)).Methods("Get")
 	router.HandleFunc("/api/staking/events",
-		handler.GetEvents).Methods("Get")
+		heightBased(handler.GetEvents)).Methods("Get")
+	router.HandleFunc("/api/staking/watchevents",
+		handler.WatchStakingEvents).Methods("Get")
+	router.HandleFunc("/api/staking/buildtransfer",
+		handler.BuildTransfer).Methods("Get")
+	router.HandleFunc("/api/staking/buildaddescrow",
+		handler.BuildAddEscrow).Methods("Get")
+	router.HandleFunc("/api/staking/buildreclaimescrow",
+		handler.BuildReclaimEscrow).Methods("Get")
+	router.HandleFunc("/api/staking/buildamendcommissionschedule",
+		handler.BuildAmendCommissionSchedule).Methods("Get")
+	router.HandleFunc("/api/staking/accounthistory",
+		handler.GetAccountHistory).Methods("Get")
+	router.HandleFunc("/api/staking/accountseries",
+		handler.GetAccountSeries).Methods("Get")
 
 	// Router Handlers to handle NodeController API Calls
 	router.HandleFunc("/api/nodecontroller/synced",
@@ -122,11 +212,13 @@ func StartServer() error {
 
 	// Router Handlers to handle Scheduler API Calls
 	router.HandleFunc("/api/scheduler/validators",
-		handler.GetValidators).Methods("Get")
+		heightBased(handler.GetValidators)).Methods("Get")
+	router.HandleFunc("/api/scheduler/validatoroverviews",
+		heightBased(handler.GetValidatorOverviews)).Methods("Get")
 	router.HandleFunc("/api/scheduler/committees",
-		handler.GetCommittees).Methods("Get")
+		heightBased(handler.GetCommittees)).Methods("Get")
 	router.HandleFunc("/api/scheduler/genesis",
-		handler.GetSchedulerStateToGenesis).Methods("Get")
+		heightBased(handler.GetSchedulerStateToGenesis)).Methods("Get")
 
 	// Router Handlers to handle Prometheus API Calls
 	router.HandleFunc("/api/prometheus/gauge",
@@ -147,3 +239,57 @@ func StartServer() error {
 	log.Fatal(graceful.ListenAndServe(":"+apiPort, router))
 	return nil
 }
+
+// heightBased wraps handler of an endpoint serving data at a given height so
+// that its responses are cached by the server and by clients.
+func heightBased(h http.HandlerFunc) http.HandlerFunc {
+	return handler.Conditional(handler.Cached(h))
+}
+
+// parseCacheConfig parses size of response cache in megabytes and seconds
+// responses at latest height are cached for, using defaults when not set.
+func parseCacheConfig(apiConf map[string]string) (int64, time.Duration,
+	error) {
+
+	cacheSize, cacheTTL := int64(64), int64(2)
+	var err error
+	if recvSize := apiConf["cache_size"]; len(recvSize) > 0 {
+		cacheSize, err = strconv.ParseInt(recvSize, 10, 64)
+		if err != nil || cacheSize < 0 {
+			return 0, 0, errors.New("cache size needs to be a " +
+				"non-negative number of megabytes")
+		}
+	}
+	if recvTTL := apiConf["cache_latest_ttl"]; len(recvTTL) > 0 {
+		cacheTTL, err = strconv.ParseInt(recvTTL, 10, 64)
+		if err != nil || cacheTTL < 0 {
+			return 0, 0, errors.New("cache latest TTL needs to be a " +
+				"non-negative number of seconds")
+		}
+	}
+	return cacheSize << 20, time.Duration(cacheTTL) * time.Second, nil
+}
+
+// parseIndexerConfig parses name of node to index, directory index is
+// stored in and height indexing starts from. Indexer is disabled when no
+// node is set.
+func parseIndexerConfig(indexerConf map[string]string) (string, string,
+	int64, error) {
+
+	nodeName := indexerConf["node_name"]
+	path := indexerConf["db_path"]
+	if len(path) == 0 {
+		path = "../indexer_db"
+	}
+
+	startHeight := int64(1)
+	if recvHeight := indexerConf["start_height"]; len(recvHeight) > 0 {
+		var err error
+		startHeight, err = strconv.ParseInt(recvHeight, 10, 64)
+		if err != nil || startHeight < 1 {
+			return "", "", 0, errors.New("indexer start height needs to " +
+				"be a positive number")
+		}
+	}
+	return nodeName, path, startHeight, nil
+}
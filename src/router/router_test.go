@@ -0,0 +1,133 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	conf "github.com/SimplyVC/oasis_api_server/src/config"
+	handler "github.com/SimplyVC/oasis_api_server/src/handlers"
+	lgr "github.com/SimplyVC/oasis_api_server/src/logger"
+	"github.com/SimplyVC/oasis_api_server/src/responses"
+)
+
+func TestMain(m *testing.M) {
+
+	// Set Logger and load configuration of nodes that endpoints are
+	// served for
+	os.Chdir("../")
+	lgr.SetLogger(os.Stdout, os.Stdout, os.Stderr)
+	conf.LoadMainConfiguration()
+	conf.LoadNodesConfiguration()
+	os.Exit(m.Run())
+}
+
+func Test_ParseCacheConfig(t *testing.T) {
+	size, ttl, err := parseCacheConfig(map[string]string{})
+	if err != nil || size != 64<<20 || ttl != 2*time.Second {
+		t.Errorf("parseCacheConfig returned wrong defaults: got %v, %v",
+			size, ttl)
+	}
+
+	size, ttl, err = parseCacheConfig(map[string]string{
+		"cache_size": "16", "cache_latest_ttl": "0"})
+	if err != nil || size != 16<<20 || ttl != 0 {
+		t.Errorf("parseCacheConfig returned wrong values: got %v, %v",
+			size, ttl)
+	}
+
+	if _, _, err := parseCacheConfig(map[string]string{
+		"cache_size": "Unicorn"}); err == nil {
+		t.Errorf("parseCacheConfig accepted invalid cache size")
+	}
+	if _, _, err := parseCacheConfig(map[string]string{
+		"cache_latest_ttl": "-1"}); err == nil {
+		t.Errorf("parseCacheConfig accepted invalid cache TTL")
+	}
+}
+
+func Test_ParseIndexerConfig(t *testing.T) {
+	node, path, start, err := parseIndexerConfig(nil)
+	if err != nil || node != "" || path != "../indexer_db" || start != 1 {
+		t.Errorf("parseIndexerConfig returned wrong defaults: got %v, %v, "+
+			"%v", node, path, start)
+	}
+
+	node, path, start, err = parseIndexerConfig(map[string]string{
+		"node_name": "Oasis_Local", "db_path": "/tmp/index",
+		"start_height": "1000"})
+	if err != nil || node != "Oasis_Local" || path != "/tmp/index" ||
+		start != 1000 {
+		t.Errorf("parseIndexerConfig returned wrong values: got %v, %v, %v",
+			node, path, start)
+	}
+
+	if _, _, _, err := parseIndexerConfig(map[string]string{
+		"start_height": "Unicorn"}); err == nil {
+		t.Errorf("parseIndexerConfig accepted invalid start height")
+	}
+}
+
+// serveHeightBased serves request for path with query using handler wrapped
+// as a height based endpoint, returning response and height it was pinned to
+func serveHeightBased(t *testing.T, h http.HandlerFunc, path string,
+	query string) (*httptest.ResponseRecorder, int64) {
+
+	req, _ := http.NewRequest("GET", path+"?"+query, nil)
+	rr := httptest.NewRecorder()
+	heightBased(h).ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	height, err := strconv.ParseInt(rr.Header().Get("X-Oasis-Height"), 10,
+		64)
+	if err != nil {
+		t.Fatalf("handler returned no height: got %v", rr.Body.String())
+	}
+	return rr, height
+}
+
+func Test_HeightBased_ValidatorUptimes(t *testing.T) {
+	rr, height := serveHeightBased(t, handler.GetValidatorUptimes,
+		"/api/consensus/validatoruptimes", "name=Oasis_Local&blocks=10")
+
+	uptimes := &responses.ValidatorUptimesResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), uptimes)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	// Request is pinned to latest height, whose commit is held by a block
+	// which doesn't exist yet, so window ends at the block before it
+	result := uptimes.ValidatorUptimes
+	if result == nil || result.Blocks != 10 ||
+		result.ToHeight != height-1 {
+		t.Errorf("handler returned unexpected body: got %v",
+			rr.Body.String())
+	}
+}
+
+func Test_HeightBased_NodeSigning(t *testing.T) {
+	rr, height := serveHeightBased(t, handler.GetNodeSigning,
+		"/api/consensus/nodesigning", "name=Oasis_Local&blocks=10")
+
+	signing := &responses.NodeSigningResponse{}
+	err := json.Unmarshal([]byte(rr.Body.String()), signing)
+	if err != nil {
+		t.Fatalf("Failed to unmarshall data")
+	}
+
+	// Window ends at the block before height, as for validator uptimes
+	result := signing.NodeSigning
+	if result == nil || result.Blocks != 10 ||
+		result.ToHeight != height-1 {
+		t.Errorf("handler returned unexpected body: got %v",
+			rr.Body.String())
+	}
+}